@@ -0,0 +1,60 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+//HasTransparency samples the alpha of every sampleStride-th pixel in both directions and reports whether any
+//of them is not fully opaque, along with the fraction of sampled pixels that are not. A transparent region of at
+//least sampleStride by sampleStride pixels is always detected. Frames without alpha, like BGRX and UYVY, are
+//opaque. A sampleStride below 1 samples every pixel.
+func HasTransparency(vf *VideoFrameV2, sampleStride int) (bool, float64) {
+	return scanAlpha(vf, sampleStride, false)
+}
+
+//AnyTransparency is HasTransparency without the fraction, it stops at the first transparent sample.
+func AnyTransparency(vf *VideoFrameV2, sampleStride int) bool {
+	transparent, _ := scanAlpha(vf, sampleStride, true)
+	return transparent
+}
+
+func scanAlpha(vf *VideoFrameV2, sampleStride int, stopEarly bool) (bool, float64) {
+	if vf == nil {
+		return false, 0
+	}
+	if sampleStride < 1 {
+		sampleStride = 1
+	}
+
+	//Where the alpha of a pixel is found.
+	var offset, rowStride, pixelStride int
+	width, height := int(vf.Xres), int(vf.Yres)
+	switch vf.FourCC {
+	case FourCCTypeBGRA:
+		offset, rowStride, pixelStride = 3, int(vf.LineStride), 4
+	case FourCCTypeUYVA:
+		offset, rowStride, pixelStride = int(vf.LineStride)*height, int(vf.LineStride/2), 1
+	default:
+		return false, 0
+	}
+
+	data := vf.data()
+	if data == nil || width <= 0 || height <= 0 || rowStride < width*pixelStride {
+		return false, 0
+	}
+
+	var sampled, transparent int
+	for y := 0; y < height; y += sampleStride {
+		row := data[offset+y*rowStride:]
+		for x := 0; x < width; x += sampleStride {
+			sampled++
+			if row[x*pixelStride] < 255 {
+				transparent++
+				if stopEarly {
+					return true, 0
+				}
+			}
+		}
+	}
+	return transparent > 0, float64(transparent) / float64(sampled)
+}
@@ -0,0 +1,43 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestHasTransparency(t *testing.T) {
+	const stride = 4
+
+	bgrx, _ := newTestVideoFrame(FourCCTypeBGRX, 16, 16, 4, func(x, y int) byte { return 0 })
+	opaque, _ := newTestVideoFrame(FourCCTypeBGRA, 16, 16, 4, func(x, y int) byte { return 255 })
+
+	//A stride by stride hole that does not line up with the sampling grid.
+	holed, data := newTestVideoFrame(FourCCTypeBGRA, 16, 16, 4, func(x, y int) byte { return 255 })
+	for y := 5; y < 5+stride; y++ {
+		for x := 9; x < 9+stride; x++ {
+			data[(y*16+x)*4+3] = 0
+		}
+	}
+
+	tests := []struct {
+		name        string
+		vf          *VideoFrameV2
+		transparent bool
+		fraction    float64
+	}{
+		{"BGRX", bgrx, false, 0},
+		{"opaque BGRA", opaque, false, 0},
+		{"BGRA with a hole", holed, true, 1.0 / 16},
+	}
+
+	for _, test := range tests {
+		transparent, fraction := HasTransparency(test.vf, stride)
+		if transparent != test.transparent || fraction != test.fraction {
+			t.Errorf("%s: Expected %v, %v but got %v, %v.", test.name, test.transparent, test.fraction, transparent, fraction)
+		}
+		if got := AnyTransparency(test.vf, stride); got != test.transparent {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.transparent, got)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+//The XML namespace of the element ancillary data is carried in within the per-frame metadata.
+const AncillaryNamespace = "urn:ndi-go:ancillary"
+
+//Ancillary is one ancillary data packet (for instance an SCTE-104 trigger) that travels with exactly one video frame.
+type Ancillary struct {
+	Type    string
+	Payload []byte
+}
+
+type ancillaryItem struct {
+	Type    string `xml:"type,attr"`
+	Payload string `xml:",chardata"`
+}
+
+type ancillaryElement struct {
+	XMLName xml.Name        `xml:"urn:ndi-go:ancillary ancillary"`
+	Items   []ancillaryItem `xml:"item"`
+}
+
+//Returns the XML element holding anc, in order. The payloads are base64 encoded.
+func MarshalAncillary(anc []Ancillary) (string, error) {
+	if len(anc) == 0 {
+		return "", nil
+	}
+
+	e := ancillaryElement{Items: make([]ancillaryItem, len(anc))}
+	for i, a := range anc {
+		e.Items[i] = ancillaryItem{a.Type, base64.StdEncoding.EncodeToString(a.Payload)}
+	}
+
+	b, err := xml.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+//Extracts the ancillary data from a per-frame metadata string. Other elements in the metadata are ignored.
+func ParseAncillary(metadata string) ([]Ancillary, error) {
+	d := xml.NewDecoder(strings.NewReader(metadata))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Space != AncillaryNamespace || start.Name.Local != "ancillary" {
+			continue
+		}
+
+		var e ancillaryElement
+		if err := d.DecodeElement(&e, &start); err != nil {
+			return nil, err
+		}
+
+		anc := make([]Ancillary, len(e.Items))
+		for i, item := range e.Items {
+			payload, err := base64.StdEncoding.DecodeString(strings.TrimSpace(item.Payload))
+			if err != nil {
+				return nil, err
+			}
+			anc[i] = Ancillary{item.Type, payload}
+		}
+		return anc, nil
+	}
+}
+
+//AttachAncillary appends the ancillary data to the per-frame metadata of vf. The new metadata is allocated by Go,
+//so this must only be used on frames that are about to be sent.
+func (vf *VideoFrameV2) AttachAncillary(anc []Ancillary) error {
+	s, err := MarshalAncillary(anc)
+	if err != nil || s == "" {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if vf.Metadata != nil {
+		buf.WriteString(goString(vf.Metadata))
+	}
+	buf.WriteString(s)
+	buf.WriteByte(0)
+	vf.Metadata = &buf.Bytes()[0]
+	return nil
+}
+
+//Ancillary returns the ancillary data carried in the per-frame metadata of vf.
+func (vf *VideoFrameV2) Ancillary() ([]Ancillary, error) {
+	if vf.Metadata == nil {
+		return nil, nil
+	}
+	return ParseAncillary(goString(vf.Metadata))
+}
+
+type AncillaryDropPolicy int
+
+const (
+	//Ancillary data of a dropped frame is attached to the next frame that is delivered, ahead of its own.
+	AncillaryReattach AncillaryDropPolicy = iota
+
+	//Ancillary data of a dropped frame is handed to the OnLost callback.
+	AncillaryReportLost
+)
+
+//AncillaryCarrier keeps ancillary data associated with frames when some frames are dropped, for instance by
+//decimation. Every frame must be passed to either Dropped or Delivered, in order. It is not safe for concurrent use.
+type AncillaryCarrier struct {
+	Policy AncillaryDropPolicy
+	OnLost func(anc []Ancillary)
+
+	//If set, lost ancillary data is also published here as an AncillaryLostEvent.
+	Bus *EventBus
+
+	pending []Ancillary
+}
+
+//Dropped records that the frame carrying anc will not be delivered.
+func (c *AncillaryCarrier) Dropped(anc []Ancillary) {
+	if len(anc) == 0 {
+		return
+	}
+
+	if c.Policy == AncillaryReportLost {
+		if c.OnLost != nil {
+			c.OnLost(anc)
+		}
+		c.Bus.Publish(AncillaryLostEvent{anc})
+		return
+	}
+	c.pending = append(c.pending, anc...)
+}
+
+//Delivered returns the ancillary data that belongs to a delivered frame which itself carried anc.
+func (c *AncillaryCarrier) Delivered(anc []Ancillary) []Ancillary {
+	if len(c.pending) == 0 {
+		return anc
+	}
+
+	out := append(c.pending, anc...)
+	c.pending = nil
+	return out
+}
@@ -0,0 +1,77 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAncillaryRoundTrip(t *testing.T) {
+	anc := []Ancillary{
+		{"scte104", []byte{0xff, 0xff, 0x00, 0x1c, '<'}},
+		{"scte104", []byte{0x01}},
+	}
+
+	metadata := []byte("<camera id=\"1\"/>\x00")
+	vf := NewVideoFrameV2()
+	vf.Metadata = &metadata[0]
+
+	if err := vf.AttachAncillary(anc); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := vf.Ancillary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, anc) {
+		t.Errorf("Expected %v but got %v.", anc, parsed)
+	}
+}
+
+func TestAncillaryCarrier(t *testing.T) {
+	frames := [][]Ancillary{
+		{{"a", []byte{1}}},
+		{{"b", []byte{2}}},
+		nil,
+		{{"c", []byte{3}}},
+	}
+
+	//Deliver every other frame, as a decimating receiver would.
+	run := func(c *AncillaryCarrier) [][]Ancillary {
+		var delivered [][]Ancillary
+		for i, anc := range frames {
+			if i%2 == 0 {
+				c.Dropped(anc)
+				continue
+			}
+			delivered = append(delivered, c.Delivered(anc))
+		}
+		return delivered
+	}
+
+	delivered := run(&AncillaryCarrier{Policy: AncillaryReattach})
+	expected := [][]Ancillary{
+		{{"a", []byte{1}}, {"b", []byte{2}}},
+		{{"c", []byte{3}}},
+	}
+	if !reflect.DeepEqual(delivered, expected) {
+		t.Errorf("Reattach: expected %v but got %v.", expected, delivered)
+	}
+
+	var lost []Ancillary
+	delivered = run(&AncillaryCarrier{Policy: AncillaryReportLost, OnLost: func(anc []Ancillary) { lost = append(lost, anc...) }})
+	expected = [][]Ancillary{
+		{{"b", []byte{2}}},
+		{{"c", []byte{3}}},
+	}
+	if !reflect.DeepEqual(delivered, expected) {
+		t.Errorf("Report lost: expected %v but got %v.", expected, delivered)
+	}
+	if !reflect.DeepEqual(lost, frames[0]) {
+		t.Errorf("Expected %v to be reported lost but got %v.", frames[0], lost)
+	}
+}
@@ -0,0 +1,116 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+//Checks a planar source or destination frame of the conversions, which must have data as soon as it has samples.
+func checkPlanarAudio(af *AudioFrameV2) error {
+	if af == nil || af.NumChannels < 0 || af.NumSamples < 0 {
+		return invalidAudioFrameErr
+	}
+	if af.NumChannels > 0 && af.NumSamples > 0 && (af.Data == nil || af.ChannelStride < af.NumSamples*4) {
+		return invalidAudioFrameErr
+	}
+	return nil
+}
+
+//Calls one of the SDK conversions, which take the source and the destination frame.
+func convertAudio(fn uintptr, src, dst unsafe.Pointer) {
+	if _, _, eno := syscall.Syscall(fn, 2, uintptr(src), uintptr(dst), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//AudioToInterleaved16sV2 converts planar float audio to interleaved 16-bit audio. The caller allocates
+//dst.Data with room for src.NumChannels*src.NumSamples samples and chooses dst.ReferenceLevel, the SDK sets the
+//sample rate, sizes and timecode of dst.
+func AudioToInterleaved16sV2(src *AudioFrameV2, dst *AudioFrameInterleaved16s) error {
+	if err := checkPlanarAudio(src); err != nil {
+		return err
+	}
+	if dst == nil || dst.Data == nil && src.NumChannels > 0 && src.NumSamples > 0 {
+		return invalidAudioFrameErr
+	}
+	convertAudio(funcPtrs.NDIlibUtilAudioToInterleaved16sV2, unsafe.Pointer(src), unsafe.Pointer(dst))
+	return nil
+}
+
+//AudioFromInterleaved16sV2 converts interleaved 16-bit audio to planar float audio, honoring src.ReferenceLevel.
+//The caller allocates dst.Data with room for src.NumChannels*src.NumSamples samples and sets dst.ChannelStride
+//to at least src.NumSamples*4, the SDK sets the sample rate, sizes and timecode of dst.
+func AudioFromInterleaved16sV2(src *AudioFrameInterleaved16s, dst *AudioFrameV2) error {
+	if src == nil || checkInterleavedAudio(src.NumChannels, src.NumSamples, src.Data != nil) != nil {
+		return invalidAudioFrameErr
+	}
+	if err := checkPlanarDestination(src.NumChannels, src.NumSamples, dst); err != nil {
+		return err
+	}
+	convertAudio(funcPtrs.NDIlibUtilAudioFromInterleaved16sV2, unsafe.Pointer(src), unsafe.Pointer(dst))
+	return nil
+}
+
+//AudioToInterleaved32sV2 is like AudioToInterleaved16sV2 but converts to 32-bit audio.
+func AudioToInterleaved32sV2(src *AudioFrameV2, dst *AudioFrameInterleaved32s) error {
+	if err := checkPlanarAudio(src); err != nil {
+		return err
+	}
+	if dst == nil || dst.Data == nil && src.NumChannels > 0 && src.NumSamples > 0 {
+		return invalidAudioFrameErr
+	}
+	convertAudio(funcPtrs.NDIlibUtilAudioToInterleaved32sV2, unsafe.Pointer(src), unsafe.Pointer(dst))
+	return nil
+}
+
+//AudioFromInterleaved32sV2 is like AudioFromInterleaved16sV2 but converts from 32-bit audio.
+func AudioFromInterleaved32sV2(src *AudioFrameInterleaved32s, dst *AudioFrameV2) error {
+	if src == nil || checkInterleavedAudio(src.NumChannels, src.NumSamples, src.Data != nil) != nil {
+		return invalidAudioFrameErr
+	}
+	if err := checkPlanarDestination(src.NumChannels, src.NumSamples, dst); err != nil {
+		return err
+	}
+	convertAudio(funcPtrs.NDIlibUtilAudioFromInterleaved32sV2, unsafe.Pointer(src), unsafe.Pointer(dst))
+	return nil
+}
+
+//AudioToInterleaved32fV2 is like AudioToInterleaved16sV2 but converts to interleaved float audio, which has no
+//reference level.
+func AudioToInterleaved32fV2(src *AudioFrameV2, dst *AudioFrameInterleaved32f) error {
+	if err := checkPlanarAudio(src); err != nil {
+		return err
+	}
+	if dst == nil || dst.Data == nil && src.NumChannels > 0 && src.NumSamples > 0 {
+		return invalidAudioFrameErr
+	}
+	convertAudio(funcPtrs.NDIlibUtilAudioToInterleaved32fV2, unsafe.Pointer(src), unsafe.Pointer(dst))
+	return nil
+}
+
+//AudioFromInterleaved32fV2 is like AudioFromInterleaved16sV2 but converts from interleaved float audio.
+func AudioFromInterleaved32fV2(src *AudioFrameInterleaved32f, dst *AudioFrameV2) error {
+	if src == nil || checkInterleavedAudio(src.NumChannels, src.NumSamples, src.Data != nil) != nil {
+		return invalidAudioFrameErr
+	}
+	if err := checkPlanarDestination(src.NumChannels, src.NumSamples, dst); err != nil {
+		return err
+	}
+	convertAudio(funcPtrs.NDIlibUtilAudioFromInterleaved32fV2, unsafe.Pointer(src), unsafe.Pointer(dst))
+	return nil
+}
+
+//Checks that dst can take numSamples of numChannels planar audio.
+func checkPlanarDestination(numChannels, numSamples int32, dst *AudioFrameV2) error {
+	if dst == nil {
+		return invalidAudioFrameErr
+	}
+	if numChannels > 0 && numSamples > 0 && (dst.Data == nil || dst.ChannelStride < numSamples*4) {
+		return invalidAudioFrameErr
+	}
+	return nil
+}
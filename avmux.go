@@ -0,0 +1,198 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var avMuxRunningErr = errors.New("mux is already running")
+
+//How long the capture loops of AVMux wait for a frame before checking whether the mux was stopped.
+const avMuxCaptureTimeoutInMs = 100
+
+//How long a timecode offset estimate is kept. Older estimates are dropped, so that clocks drifting apart are
+//followed.
+const avMuxOffsetWindow = 5 * time.Second
+
+//The parts of RecvInstance and SendInstance that AVMux uses.
+type avMuxReceiver interface {
+	CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType
+	FreeVideoV2(vf *VideoFrameV2)
+	FreeAudioV2(af *AudioFrameV2)
+}
+
+type avMuxSender interface {
+	SendVideoV2(frame *VideoFrameV2)
+	SendAudioV2(frame *AudioFrameV2) error
+}
+
+//AVMux sends the video of one receiver together with the audio of another, for example to replace the audio of a
+//camera with that of a separate microphone source. Audio timecodes are moved onto the timeline of the video source,
+//so that receivers see both as one source. The audio receiver should be created with RecvBandwidthAudioOnly, as its
+//video is dropped anyway.
+type AVMux struct {
+	video, audio avMuxReceiver
+	send         avMuxSender
+	align        timecodeAligner
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewAVMux(videoRecv, audioRecv *RecvInstance, sendInst *SendInstance) *AVMux {
+	return newAVMux(videoRecv, audioRecv, sendInst)
+}
+
+func newAVMux(video, audio avMuxReceiver, send avMuxSender) *AVMux {
+	return &AVMux{video: video, audio: audio, send: send}
+}
+
+//Start forwards frames until ctx is done or Stop is called and returns once both sources have stopped. Returns
+//ctx.Err() if ctx ended it, nil after Stop and the error of the sender if it rejected an audio frame.
+func (m *AVMux) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.mu.Unlock()
+		return avMuxRunningErr
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.cancel = nil
+		m.mu.Unlock()
+		cancel()
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		audioErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		m.runVideo(runCtx)
+	}()
+	go func() {
+		defer wg.Done()
+		if audioErr = m.runAudio(runCtx); audioErr != nil {
+			cancel()
+		}
+	}()
+	wg.Wait()
+
+	if audioErr != nil {
+		return audioErr
+	}
+	return ctx.Err()
+}
+
+//Stop makes a running Start return. It does nothing if the mux is not running.
+func (m *AVMux) Stop() {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.mu.Unlock()
+}
+
+func (m *AVMux) runVideo(ctx context.Context) {
+	var vf VideoFrameV2
+	for ctx.Err() == nil {
+		if m.video.CaptureV2(&vf, nil, nil, avMuxCaptureTimeoutInMs) != FrameTypeVideo {
+			continue
+		}
+		m.align.observeVideo(vf.Timecode, sysClock.Now())
+		m.send.SendVideoV2(&vf)
+		m.video.FreeVideoV2(&vf)
+	}
+}
+
+func (m *AVMux) runAudio(ctx context.Context) error {
+	var af AudioFrameV2
+	for ctx.Err() == nil {
+		if m.audio.CaptureV2(nil, &af, nil, avMuxCaptureTimeoutInMs) != FrameTypeAudio {
+			continue
+		}
+		m.align.observeAudio(af.Timecode, sysClock.Now())
+		af.Timecode = m.align.audioTimecode(af.Timecode)
+		err := m.send.SendAudioV2(&af)
+		m.audio.FreeAudioV2(&af)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//timecodeAligner maps the timecodes of one source onto those of another. Each source's offset to the local clock
+//is estimated as the largest difference between a timecode and the time it arrived, which is the one with the
+//least network delay.
+type timecodeAligner struct {
+	mu           sync.Mutex
+	video, audio offsetEstimate
+}
+
+type offsetEstimate struct {
+	cur, prev   int64
+	hasCur      bool
+	hasPrev     bool
+	windowStart time.Time
+}
+
+func (e *offsetEstimate) observe(timecode int64, now time.Time) {
+	if timecode == SendTimecodeSynthesize || timecode == SendTimecodeEmpty {
+		return
+	}
+
+	if !e.hasCur || now.Sub(e.windowStart) >= avMuxOffsetWindow {
+		e.prev, e.hasPrev = e.cur, e.hasCur
+		e.hasCur, e.windowStart = false, now
+	}
+
+	offset := timecode - now.UnixNano()/100
+	if !e.hasCur || offset > e.cur {
+		e.cur, e.hasCur = offset, true
+	}
+}
+
+func (e *offsetEstimate) get() (int64, bool) {
+	if e.hasPrev && e.prev > e.cur {
+		return e.prev, true
+	}
+	return e.cur, e.hasCur
+}
+
+func (a *timecodeAligner) observeVideo(timecode int64, now time.Time) {
+	a.mu.Lock()
+	a.video.observe(timecode, now)
+	a.mu.Unlock()
+}
+
+func (a *timecodeAligner) observeAudio(timecode int64, now time.Time) {
+	a.mu.Lock()
+	a.audio.observe(timecode, now)
+	a.mu.Unlock()
+}
+
+//Returns the audio timecode on the video timeline, or SendTimecodeSynthesize to let the SDK pick one until both
+//sources have been seen.
+func (a *timecodeAligner) audioTimecode(timecode int64) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	video, ok := a.video.get()
+	audio, ok2 := a.audio.get()
+	if !ok || !ok2 || timecode == SendTimecodeSynthesize || timecode == SendTimecodeEmpty {
+		return SendTimecodeSynthesize
+	}
+	return timecode - audio + video
+}
@@ -0,0 +1,172 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimecodeAligner(t *testing.T) {
+	var a timecodeAligner
+	start := time.Unix(1000, 0)
+	local := func(d time.Duration) int64 { return start.Add(d).UnixNano() / 100 }
+
+	if tc := a.audioTimecode(123); tc != SendTimecodeSynthesize {
+		t.Errorf("Expected a synthesized timecode before any frame but got %d.", tc)
+	}
+
+	//The video source is 1s ahead of the local clock and the audio source 3s behind. The first frames are delayed
+	//by the network.
+	const videoOffset, audioOffset = 10000000, -30000000
+	a.observeVideo(local(0)+videoOffset, start.Add(40*time.Millisecond))
+	a.observeAudio(local(0)+audioOffset, start.Add(25*time.Millisecond))
+	a.observeVideo(local(40*time.Millisecond)+videoOffset, start.Add(40*time.Millisecond))
+	a.observeAudio(local(20*time.Millisecond)+audioOffset, start.Add(20*time.Millisecond))
+
+	audio := local(time.Second) + audioOffset
+	if tc, want := a.audioTimecode(audio), local(time.Second)+videoOffset; tc != want {
+		t.Errorf("Expected timecode %d but got %d.", want, tc)
+	}
+
+	//The audio clock runs 10ms slow, the old estimate is dropped after two windows.
+	late := 3 * avMuxOffsetWindow
+	for d := avMuxOffsetWindow; d <= late; d += avMuxOffsetWindow {
+		a.observeVideo(local(d)+videoOffset, start.Add(d))
+		a.observeAudio(local(d)+audioOffset-100000, start.Add(d))
+	}
+	audio = local(late) + audioOffset - 100000
+	if tc, want := a.audioTimecode(audio), local(late)+videoOffset; tc != want {
+		t.Errorf("Expected timecode %d after drift but got %d.", want, tc)
+	}
+
+	if tc := a.audioTimecode(SendTimecodeSynthesize); tc != SendTimecodeSynthesize {
+		t.Errorf("Expected a synthesized timecode to be kept but got %d.", tc)
+	}
+}
+
+//Delivers frames of one type with increasing timecodes. With a limit, drained is closed once that many frames
+//were delivered and no more frames follow. If after is set, the first frame waits until it is closed.
+type timecodeReceiver struct {
+	frameType FrameType
+	timecode  int64
+	limit     int
+	drained   chan struct{}
+	after     <-chan struct{}
+
+	delivered int
+	mu        sync.Mutex
+	freed     int
+}
+
+func (r *timecodeReceiver) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	if r.limit > 0 && r.delivered == r.limit {
+		return FrameTypeNone
+	}
+	if r.delivered == 0 && r.after != nil {
+		<-r.after
+	}
+	r.delivered++
+	if r.delivered == r.limit {
+		defer close(r.drained)
+	}
+
+	r.timecode += 10000
+	if r.frameType == FrameTypeVideo && vf != nil {
+		vf.Timecode = r.timecode
+	} else if r.frameType == FrameTypeAudio && af != nil {
+		af.Timecode = r.timecode
+	} else {
+		return FrameTypeNone
+	}
+	return r.frameType
+}
+
+func (r *timecodeReceiver) FreeVideoV2(*VideoFrameV2) { r.free() }
+func (r *timecodeReceiver) FreeAudioV2(*AudioFrameV2) { r.free() }
+
+func (r *timecodeReceiver) free() {
+	r.mu.Lock()
+	r.freed++
+	r.mu.Unlock()
+}
+
+type recordingSender struct {
+	mu            sync.Mutex
+	video, audio  []int64
+	audioErrAfter int
+}
+
+func (s *recordingSender) SendVideoV2(frame *VideoFrameV2) {
+	s.mu.Lock()
+	s.video = append(s.video, frame.Timecode)
+	s.mu.Unlock()
+}
+
+func (s *recordingSender) SendAudioV2(frame *AudioFrameV2) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.audioErrAfter > 0 && len(s.audio) == s.audioErrAfter {
+		return invalidAudioFrameErr
+	}
+	s.audio = append(s.audio, frame.Timecode)
+	return nil
+}
+
+func TestAVMux(t *testing.T) {
+	video := &timecodeReceiver{frameType: FrameTypeVideo, timecode: 5000000000, limit: 20, drained: make(chan struct{})}
+	//Audio starts once the video timeline is known.
+	audio := &timecodeReceiver{frameType: FrameTypeAudio, limit: 20, drained: make(chan struct{}), after: video.drained}
+	send := &recordingSender{}
+	m := newAVMux(video, audio, send)
+
+	done := make(chan error)
+	go func() { done <- m.Start(context.Background()) }()
+
+	//The capture loops only run once Start has marked the mux as running.
+	<-video.drained
+	<-audio.drained
+	if err := m.Start(context.Background()); err != avMuxRunningErr {
+		t.Errorf("Expected %v but got %v.", avMuxRunningErr, err)
+	}
+
+	m.Stop()
+	if err := <-done; err != nil {
+		t.Errorf("Expected nil after Stop but got %v.", err)
+	}
+
+	send.mu.Lock()
+	defer send.mu.Unlock()
+	if len(send.video) != 20 || len(send.audio) != 20 {
+		t.Fatalf("Expected 20 video and audio frames to be sent but got %d and %d.", len(send.video), len(send.audio))
+	}
+	if video.freed != len(send.video) || audio.freed != len(send.audio) {
+		t.Errorf("Expected every frame to be freed but freed %d of %d video and %d of %d audio frames.", video.freed, len(send.video), audio.freed, len(send.audio))
+	}
+
+	//Audio is moved onto the video timeline, which is far ahead.
+	last := send.audio[len(send.audio)-1]
+	if last == SendTimecodeSynthesize || last < 4000000000 {
+		t.Errorf("Expected audio timecodes on the video timeline but got %d.", last)
+	}
+}
+
+func TestAVMuxErrors(t *testing.T) {
+	send := &recordingSender{audioErrAfter: 3}
+	m := newAVMux(&timecodeReceiver{frameType: FrameTypeVideo}, &timecodeReceiver{frameType: FrameTypeAudio}, send)
+	if err := m.Start(context.Background()); err != invalidAudioFrameErr {
+		t.Errorf("Expected %v but got %v.", invalidAudioFrameErr, err)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(0, 0))
+	defer cancel()
+	m = newAVMux(&timecodeReceiver{}, &timecodeReceiver{}, &recordingSender{})
+	if err := m.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v but got %v.", context.DeadlineExceeded, err)
+	}
+}
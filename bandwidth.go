@@ -0,0 +1,29 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+//Rough compression ratio of the NDI codec against the uncompressed UYVY frames a receiver hands back. NDI's
+//published typical bitrate for a 1080p60 full bandwidth stream is about 130Mbps, against about 2Gbps of UYVY,
+//which is 15:1. The SDK documents RecvBandwidthLowest as a proxy stream of reduced resolution, 640 pixels wide,
+//with the same codec. That scaling is already contained in the size of the received proxy frames, so the same
+//ratio applies to both modes.
+const compressionRatio = 15.0
+
+//EstimateRecvBitrate returns an ESTIMATE, in bits per second, of the network bandwidth a receiver uses for video.
+//frameSize is the size in bytes of the frames as received (LineStride*Yres, before any conversion) and fps is
+//the measured frame rate. The result is derived from the known NDI compression characteristics of the bandwidth
+//mode and is only suitable for network planning, not accounting. Metadata and audio only receivers yield 0.
+func EstimateRecvBitrate(frameSize int, fps float64, bandwidth RecvBandwidth) float64 {
+	if frameSize <= 0 || fps <= 0 {
+		return 0
+	}
+
+	switch bandwidth {
+	case RecvBandwidthHighest, RecvBandwidthLowest:
+		return float64(frameSize) * 8 * fps / compressionRatio
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,39 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateRecvBitrate(t *testing.T) {
+	const (
+		fullHD = 1920 * 1080 * 2
+		proxy  = 640 * 360 * 2
+	)
+
+	tests := []struct {
+		frameSize int
+		fps       float64
+		bandwidth RecvBandwidth
+		mbps      float64
+	}{
+		{fullHD, 60, RecvBandwidthHighest, 132.71},
+		{fullHD, 30, RecvBandwidthHighest, 66.36},
+		{proxy, 60, RecvBandwidthLowest, 14.75},
+		{fullHD, 60, RecvBandwidthAudioOnly, 0},
+		{fullHD, 60, RecvBandwidthMetadataOnly, 0},
+		{0, 60, RecvBandwidthHighest, 0},
+		{fullHD, 0, RecvBandwidthHighest, 0},
+	}
+
+	for _, tt := range tests {
+		mbps := EstimateRecvBitrate(tt.frameSize, tt.fps, tt.bandwidth) / 1e6
+		if math.Abs(mbps-tt.mbps) > 0.01 {
+			t.Errorf("EstimateRecvBitrate(%d, %v, %d) = %.2fMbps, expected %.2fMbps.", tt.frameSize, tt.fps, tt.bandwidth, mbps, tt.mbps)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"math"
+)
+
+var invalidBlendWeightsErr = errors.New("need one weight per frame and the weights must sum to 1")
+
+//How far the sum of the weights of BlendFrames may be off 1, to allow for rounding like 1/3+1/3+1/3.
+const blendWeightTolerance = 1e-4
+
+//BlendFrames returns the weighted average of frames, which gives motion blur for slow motion or less noise when
+//averaging frames of a static scene. There must be one weight per frame and the weights must sum to 1. The frames
+//must be BGRA, BGRX or UYVY of the same resolution. The returned frame takes its properties, like the timecode, from
+//the first frame. It owns its data and carries no metadata.
+func BlendFrames(frames []*VideoFrameV2, weights []float32) (*VideoFrameV2, error) {
+	if len(frames) == 0 || len(frames) != len(weights) {
+		return nil, invalidBlendWeightsErr
+	}
+	var sum float64
+	for _, w := range weights {
+		sum += float64(w)
+	}
+	if math.Abs(sum-1) > blendWeightTolerance {
+		return nil, invalidBlendWeightsErr
+	}
+
+	first := frames[0]
+	if first == nil || first.Xres <= 0 || first.Yres <= 0 {
+		return nil, invalidVideoFrameErr
+	}
+	unitBytes, unitPixels, ok := pixelUnit(first.FourCC)
+	if !ok {
+		return nil, unsupportedFourCCErr
+	}
+
+	rowLen := int(first.Xres) / unitPixels * unitBytes
+	height := int(first.Yres)
+	data := make([][]byte, len(frames))
+	for i, f := range frames {
+		if f == nil {
+			return nil, invalidVideoFrameErr
+		}
+		if f.Xres != first.Xres || f.Yres != first.Yres || f.FourCC != first.FourCC {
+			return nil, frameMismatchErr
+		}
+		if data[i] = f.data(); data[i] == nil || int(f.LineStride) < rowLen {
+			return nil, invalidVideoFrameErr
+		}
+	}
+
+	out := make([]byte, rowLen*height)
+	acc := make([]float32, rowLen)
+	for y := 0; y < height; y++ {
+		for x := range acc {
+			acc[x] = 0
+		}
+		for i, f := range frames {
+			row := data[i][y*int(f.LineStride) : y*int(f.LineStride)+rowLen]
+			w := weights[i]
+			for x, v := range row {
+				acc[x] += w * float32(v)
+			}
+		}
+
+		outRow := out[y*rowLen : (y+1)*rowLen]
+		for x, v := range acc {
+			outRow[x] = clampByte(v)
+		}
+	}
+
+	dst := *first
+	dst.LineStride = int32(rowLen)
+	dst.Data = &out[0]
+	dst.Metadata = nil
+	return &dst, nil
+}
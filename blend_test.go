@@ -0,0 +1,67 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestBlendFrames(t *testing.T) {
+	constant := func(v byte) *VideoFrameV2 {
+		vf, _ := newTestVideoFrame(FourCCTypeUYVY, 4, 2, 2, func(x, y int) byte { return v })
+		return vf
+	}
+	a, b, c := constant(30), constant(60), constant(240)
+	a.Timecode = 42
+
+	out, err := BlendFrames([]*VideoFrameV2{a, b, c}, []float32{1.0 / 3, 1.0 / 3, 1.0 / 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Timecode != 42 || out.FourCC != FourCCTypeUYVY || out.LineStride != 8 {
+		t.Errorf("Expected the properties of the first frame but got %+v.", out)
+	}
+	for i, v := range out.ReadData() {
+		if v != 110 {
+			t.Fatalf("Expected 110 at byte %d but got %d.", i, v)
+		}
+	}
+
+	//Padding at the end of the rows is skipped.
+	padded := constant(0)
+	data := make([]byte, 12*2)
+	for i := range data {
+		data[i] = 200
+		if i%12 >= 8 {
+			data[i] = 0
+		}
+	}
+	padded.LineStride, padded.Data = 12, &data[0]
+	out, err = BlendFrames([]*VideoFrameV2{padded, b}, []float32{0.25, 0.75})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := out.ReadData(); len(d) != 16 || d[0] != 95 || d[15] != 95 {
+		t.Errorf("Expected 16 bytes of 95 but got %v.", d)
+	}
+
+	bgra, _ := newTestVideoFrame(FourCCTypeBGRA, 4, 2, 4, func(x, y int) byte { return 0 })
+	tests := []struct {
+		name    string
+		frames  []*VideoFrameV2
+		weights []float32
+		err     error
+	}{
+		{"no frames", nil, nil, invalidBlendWeightsErr},
+		{"missing weight", []*VideoFrameV2{a, b}, []float32{1}, invalidBlendWeightsErr},
+		{"sum", []*VideoFrameV2{a, b}, []float32{0.5, 0.6}, invalidBlendWeightsErr},
+		{"nil frame", []*VideoFrameV2{a, nil}, []float32{0.5, 0.5}, invalidVideoFrameErr},
+		{"mismatch", []*VideoFrameV2{a, bgra}, []float32{0.5, 0.5}, frameMismatchErr},
+		{"fourCC", []*VideoFrameV2{{FourCC: FourCCTypeUYVA, Xres: 2, Yres: 2}}, []float32{1}, unsupportedFourCCErr},
+	}
+	for _, test := range tests {
+		if _, err := BlendFrames(test.frames, test.weights); err != test.err {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.err, err)
+		}
+	}
+}
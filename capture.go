@@ -0,0 +1,61 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "context"
+
+//How long a single capture call blocks at most while a context is watched.
+const capturePollInMs = 50
+
+//Splits a capture of up to timeoutInMs into calls of at most capturePollInMs, checking ctx in between. Returns
+//the first frame type other than FrameTypeNone, FrameTypeNone once the timeout is used up or ctx.Err() if ctx
+//is done first.
+func captureWithContext(ctx context.Context, timeoutInMs uint32, capture func(timeoutInMs uint32) FrameType) (FrameType, error) {
+	remaining := timeoutInMs
+	for {
+		if err := ctx.Err(); err != nil {
+			return FrameTypeNone, err
+		}
+
+		poll := remaining
+		if poll > capturePollInMs {
+			poll = capturePollInMs
+		}
+		if ft := capture(poll); ft != FrameTypeNone {
+			return ft, nil
+		}
+
+		remaining -= poll
+		if remaining == 0 {
+			return FrameTypeNone, ctx.Err()
+		}
+	}
+}
+
+//The parts of RecvInstance that captureLatest uses.
+type latestReceiver interface {
+	CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType
+	FreeVideoV2(vf *VideoFrameV2)
+}
+
+//Implements RecvInstance.CaptureLatest.
+func captureLatest(recv latestReceiver, vf *VideoFrameV2, timeoutInMs uint32) (FrameType, int) {
+	ft := recv.CaptureV2(vf, nil, nil, timeoutInMs)
+	if ft != FrameTypeVideo {
+		return ft, 0
+	}
+
+	var skipped int
+	for {
+		var next VideoFrameV2
+		if recv.CaptureV2(&next, nil, nil, 0) != FrameTypeVideo {
+			return ft, skipped
+		}
+
+		recv.FreeVideoV2(vf)
+		*vf = next
+		skipped++
+	}
+}
@@ -0,0 +1,112 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//Stands in for a receiver that never gets a frame.
+func idleCapture(timeoutInMs uint32) FrameType {
+	sysClock.Sleep(time.Duration(timeoutInMs) * time.Millisecond)
+	return FrameTypeNone
+}
+
+func TestCaptureWithContextCancel(t *testing.T) {
+	clock := useFakeClock(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	//Cancelled 120ms into a capture of 10s.
+	start := clock.Now()
+	ft, err := captureWithContext(ctx, 10000, func(timeoutInMs uint32) FrameType {
+		ft := idleCapture(timeoutInMs)
+		if clock.Now().Sub(start) >= 120*time.Millisecond {
+			cancel()
+		}
+		return ft
+	})
+	if ft != FrameTypeNone || err != context.Canceled {
+		t.Errorf("Expected %v, %v but got %v, %v.", FrameTypeNone, context.Canceled, ft, err)
+	}
+	if elapsed := clock.Now().Sub(start); elapsed != 150*time.Millisecond {
+		t.Errorf("Expected the capture to stop at the end of the poll it was cancelled in but it took %v.", elapsed)
+	}
+}
+
+func TestCaptureWithContextTimeout(t *testing.T) {
+	var polls []uint32
+	ft, err := captureWithContext(context.Background(), 120, func(timeoutInMs uint32) FrameType {
+		polls = append(polls, timeoutInMs)
+		return FrameTypeNone
+	})
+	if ft != FrameTypeNone || err != nil {
+		t.Errorf("Expected %v, nil but got %v, %v.", FrameTypeNone, ft, err)
+	}
+	if len(polls) != 3 || polls[0] != 50 || polls[1] != 50 || polls[2] != 20 {
+		t.Errorf("Expected polls of 50, 50 and 20ms but got %v.", polls)
+	}
+
+	ft, err = captureWithContext(context.Background(), 1000, func(uint32) FrameType { return FrameTypeVideo })
+	if ft != FrameTypeVideo || err != nil {
+		t.Errorf("Expected %v, nil but got %v, %v.", FrameTypeVideo, ft, err)
+	}
+}
+
+//Hands out the queued frames, told apart by their timecode, and records which ones were freed.
+type queuedReceiver struct {
+	queue []FrameType
+	next  int64
+	freed []int64
+}
+
+func (r *queuedReceiver) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	if len(r.queue) == 0 {
+		return FrameTypeNone
+	}
+	ft := r.queue[0]
+	r.queue = r.queue[1:]
+
+	r.next++
+	if ft == FrameTypeVideo {
+		*vf = VideoFrameV2{Timecode: r.next}
+	}
+	return ft
+}
+
+func (r *queuedReceiver) FreeVideoV2(vf *VideoFrameV2) {
+	r.freed = append(r.freed, vf.Timecode)
+}
+
+func TestCaptureLatest(t *testing.T) {
+	v := FrameTypeVideo
+	recv := &queuedReceiver{queue: []FrameType{v, v, v, v, FrameTypeAudio, v}}
+
+	var vf VideoFrameV2
+	ft, skipped := captureLatest(recv, &vf, 100)
+	if ft != FrameTypeVideo || skipped != 3 {
+		t.Errorf("Expected a video frame with 3 skipped but got %v with %d skipped.", ft, skipped)
+	}
+	if vf.Timecode != 4 {
+		t.Errorf("Expected the newest frame 4 but got %d.", vf.Timecode)
+	}
+	if len(recv.freed) != 3 || recv.freed[0] != 1 || recv.freed[1] != 2 || recv.freed[2] != 3 {
+		t.Errorf("Expected frames 1 to 3 to be freed but got %v.", recv.freed)
+	}
+
+	//The audio frame ended the draining and is not captured again.
+	recv.freed = nil
+	ft, skipped = captureLatest(recv, &vf, 100)
+	if ft != FrameTypeVideo || skipped != 0 || vf.Timecode != 6 || len(recv.freed) != 0 {
+		t.Errorf("Expected frame 6 alone but got %v, frame %d with %d skipped and %v freed.", ft, vf.Timecode, skipped, recv.freed)
+	}
+
+	ft, skipped = captureLatest(recv, &vf, 100)
+	if ft != FrameTypeNone || skipped != 0 {
+		t.Errorf("Expected %v without skipped frames but got %v with %d skipped.", FrameTypeNone, ft, skipped)
+	}
+}
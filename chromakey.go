@@ -0,0 +1,78 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"math"
+)
+
+var frameMismatchErr = errors.New("frames differ in resolution or FourCC")
+
+//Returns the BT.709 chroma of an RGB color, normalized to 0..1.
+func chroma(r, g, b float64) (cb, cr float64) {
+	cb = (-0.1146*r-0.3854*g+0.5*b)/255 + 0.5
+	cr = (0.5*r-0.4542*g-0.0458*b)/255 + 0.5
+	return
+}
+
+//ChromaKey composites fg over bg, replacing the pixels of fg whose chroma is close to keyColor (R, G, B) with
+//the pixels of bg. Pixels closer than similarity are fully replaced, over the following smoothness they fade
+//back to fg. Both are chroma distances in the range 0..1. The frames must be BGRA or BGRX of the same
+//resolution. The returned frame has the FourCC of fg and owns its data.
+func ChromaKey(fg, bg *VideoFrameV2, keyColor [3]byte, similarity, smoothness float32) (*VideoFrameV2, error) {
+	if fg == nil || bg == nil {
+		return nil, invalidVideoFrameErr
+	}
+	if fg.FourCC != FourCCTypeBGRA && fg.FourCC != FourCCTypeBGRX {
+		return nil, unsupportedFourCCErr
+	}
+	if fg.Xres != bg.Xres || fg.Yres != bg.Yres || fg.FourCC != bg.FourCC {
+		return nil, frameMismatchErr
+	}
+
+	width, height := int(fg.Xres), int(fg.Yres)
+	fgData, bgData := fg.data(), bg.data()
+	if fgData == nil || bgData == nil || int(fg.LineStride) < width*4 || int(bg.LineStride) < width*4 {
+		return nil, invalidVideoFrameErr
+	}
+
+	keyCb, keyCr := chroma(float64(keyColor[0]), float64(keyColor[1]), float64(keyColor[2]))
+
+	stride := width * 4
+	out := make([]byte, stride*height)
+	for y := 0; y < height; y++ {
+		fgRow := fgData[y*int(fg.LineStride):]
+		bgRow := bgData[y*int(bg.LineStride):]
+		outRow := out[y*stride:]
+
+		for x := 0; x < stride; x += 4 {
+			b, g, r := float64(fgRow[x]), float64(fgRow[x+1]), float64(fgRow[x+2])
+			cb, cr := chroma(r, g, b)
+			dist := math.Hypot(cb-keyCb, cr-keyCr)
+
+			//The share of the foreground that is kept.
+			var alpha float64
+			switch {
+			case dist <= float64(similarity):
+				alpha = 0
+			case dist >= float64(similarity+smoothness):
+				alpha = 1
+			default:
+				alpha = (dist - float64(similarity)) / float64(smoothness)
+			}
+
+			for c := 0; c < 4; c++ {
+				outRow[x+c] = clampByte(float32(alpha*float64(fgRow[x+c]) + (1-alpha)*float64(bgRow[x+c])))
+			}
+		}
+	}
+
+	dst := *fg
+	dst.LineStride = int32(stride)
+	dst.Data = &out[0]
+	dst.Metadata = nil
+	return &dst, nil
+}
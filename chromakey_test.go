@@ -0,0 +1,44 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestChromaKey(t *testing.T) {
+	//Left half green screen, right half a red subject.
+	fg, fgData := newTestVideoFrame(FourCCTypeBGRA, 4, 2, 4, func(x, y int) byte { return 0 })
+	for i := 0; i < len(fgData); i += 4 {
+		if (i/4)%4 < 2 {
+			fgData[i+1] = 255
+		} else {
+			fgData[i+2] = 200
+		}
+		fgData[i+3] = 255
+	}
+
+	bg, _ := newTestVideoFrame(FourCCTypeBGRA, 4, 2, 4, func(x, y int) byte { return 50 })
+
+	out, err := ChromaKey(fg, bg, [3]byte{0, 255, 0}, 0.1, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := out.data()
+	for px := 0; px < 8; px++ {
+		b, g, r := data[px*4], data[px*4+1], data[px*4+2]
+		if px%4 < 2 {
+			if b != 50 || g != 50 || r != 50 {
+				t.Errorf("Pixel %d should show the background but is %d,%d,%d.", px, r, g, b)
+			}
+		} else if r != 200 || g != 0 || b != 0 {
+			t.Errorf("Pixel %d should show the foreground but is %d,%d,%d.", px, r, g, b)
+		}
+	}
+
+	small, _ := newTestVideoFrame(FourCCTypeBGRA, 2, 2, 4, func(x, y int) byte { return 0 })
+	if _, err := ChromaKey(fg, small, [3]byte{0, 255, 0}, 0.1, 0.1); err != frameMismatchErr {
+		t.Errorf("Expected %v but got %v.", frameMismatchErr, err)
+	}
+}
@@ -0,0 +1,22 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"testing"
+
+	"github.com/FlowingSPDG/ndi-go/nditest"
+)
+
+var _ clock = (*nditest.FakeClock)(nil)
+
+//useFakeClock replaces sysClock with a fake clock for the duration of the test.
+func useFakeClock(t *testing.T) *nditest.FakeClock {
+	c := nditest.NewFakeClock()
+	prev := sysClock
+	sysClock = c
+	t.Cleanup(func() { sysClock = prev })
+	return c
+}
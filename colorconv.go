@@ -0,0 +1,85 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+//Coefficients converting limited range YCbCr to RGB.
+type ycbcrMatrix struct {
+	rCr, gCb, gCr, bCb float32
+}
+
+var (
+	bt709 = ycbcrMatrix{1.792741, 0.213249, 0.532909, 2.112402}
+	bt601 = ycbcrMatrix{1.596027, 0.391762, 0.812968, 2.017232}
+)
+
+//Scales limited range luma to full range.
+const lumaScale = 255.0 / 219
+
+//UYVYToBGRA converts a UYVY or UYVA frame to BGRA using the BT.709 matrix that NDI uses for HD and larger
+//formats. Chroma is shared by each pair of pixels. UYVY frames come out opaque, UYVA frames keep their alpha.
+//The returned frame owns its data and carries no metadata.
+func UYVYToBGRA(src *VideoFrameV2) (*VideoFrameV2, error) {
+	return uyvyToBGRA(src, bt709)
+}
+
+//UYVYToBGRABT601 is UYVYToBGRA using the BT.601 matrix of standard definition video.
+func UYVYToBGRABT601(src *VideoFrameV2) (*VideoFrameV2, error) {
+	return uyvyToBGRA(src, bt601)
+}
+
+func uyvyToBGRA(src *VideoFrameV2, m ycbcrMatrix) (*VideoFrameV2, error) {
+	if src == nil || src.Xres <= 0 || src.Yres <= 0 || src.Xres%2 != 0 {
+		return nil, invalidVideoFrameErr
+	}
+	if src.FourCC != FourCCTypeUYVY && src.FourCC != FourCCTypeUYVA {
+		return nil, unsupportedFourCCErr
+	}
+
+	width, height := int(src.Xres), int(src.Yres)
+	srcStride := int(src.LineStride)
+	srcData := src.data()
+	if srcData == nil || srcStride < width*2 {
+		return nil, invalidVideoFrameErr
+	}
+
+	//The alpha plane of UYVA follows the YCbCr plane.
+	var alpha []byte
+	if src.FourCC == FourCCTypeUYVA {
+		alpha = srcData[srcStride*height:]
+	}
+
+	stride := width * 4
+	out := make([]byte, stride*height)
+	for y := 0; y < height; y++ {
+		row := srcData[y*srcStride:]
+		dst := out[y*stride:]
+		for x := 0; x < width; x += 2 {
+			u := float32(row[x*2]) - 128
+			v := float32(row[x*2+2]) - 128
+			r := m.rCr * v
+			g := -m.gCb*u - m.gCr*v
+			b := m.bCb * u
+
+			for i := 0; i < 2; i++ {
+				luma := (float32(row[x*2+1+i*2]) - 16) * lumaScale
+				px := dst[(x+i)*4:]
+				px[0] = clampByte(luma + b)
+				px[1] = clampByte(luma + g)
+				px[2] = clampByte(luma + r)
+				px[3] = 255
+				if alpha != nil {
+					px[3] = alpha[y*(srcStride/2)+x+i]
+				}
+			}
+		}
+	}
+
+	dst := *src
+	dst.FourCC = FourCCTypeBGRA
+	dst.LineStride = int32(stride)
+	dst.Data = &out[0]
+	dst.Metadata = nil
+	return &dst, nil
+}
@@ -0,0 +1,59 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestUYVYToBGRA(t *testing.T) {
+	tests := []struct {
+		name    string
+		convert func(*VideoFrameV2) (*VideoFrameV2, error)
+		uyvy    [4]byte
+		bgra    [4]byte
+	}{
+		{"BT.709 white", UYVYToBGRA, [4]byte{128, 235, 128, 235}, [4]byte{255, 255, 255, 255}},
+		{"BT.709 black", UYVYToBGRA, [4]byte{128, 16, 128, 16}, [4]byte{0, 0, 0, 255}},
+		{"BT.709 red", UYVYToBGRA, [4]byte{102, 63, 240, 63}, [4]byte{0, 0, 255, 255}},
+		{"BT.709 blue", UYVYToBGRA, [4]byte{240, 32, 118, 32}, [4]byte{255, 0, 0, 255}},
+		{"BT.601 red", UYVYToBGRABT601, [4]byte{90, 81, 240, 81}, [4]byte{0, 0, 255, 255}},
+		{"BT.601 gray", UYVYToBGRABT601, [4]byte{128, 126, 128, 126}, [4]byte{128, 128, 128, 255}},
+	}
+
+	for _, test := range tests {
+		src, _ := newTestVideoFrame(FourCCTypeUYVY, 2, 2, 2, func(x, y int) byte { return 0 })
+		data := src.data()
+		for i := range data {
+			data[i] = test.uyvy[i%4]
+		}
+
+		dst, err := test.convert(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dst.FourCC != FourCCTypeBGRA || dst.LineStride != 8 {
+			t.Fatalf("%s: Expected a BGRA frame with a stride of 8 but got %s with %d.", test.name, dst.FourCC, dst.LineStride)
+		}
+		for px, out := 0, dst.data(); px < 4; px++ {
+			if got := [4]byte{out[px*4], out[px*4+1], out[px*4+2], out[px*4+3]}; !closeBGRA(got, test.bgra) {
+				t.Errorf("%s: Expected pixel %d to be %v but got %v.", test.name, px, test.bgra, got)
+			}
+		}
+	}
+
+	bgra, _ := newTestVideoFrame(FourCCTypeBGRA, 2, 2, 4, func(x, y int) byte { return 0 })
+	if _, err := UYVYToBGRA(bgra); err != unsupportedFourCCErr {
+		t.Errorf("Expected %v but got %v.", unsupportedFourCCErr, err)
+	}
+}
+
+//Allows for the rounding of the 8 bit reference values.
+func closeBGRA(a, b [4]byte) bool {
+	for i := range a {
+		if d := int(a[i]) - int(b[i]); d < -1 || d > 1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+//Package config loads ndi pipeline configurations from TOML files.
+//
+//The keys are the JSON names of ndi.PipelineConfig, for example:
+//
+//	[[senders]]
+//	name = "PROGRAM"
+//
+//	[[receivers]]
+//	name = "cam1"
+//	source = "CAM1 (Chan 1)"
+//	bandwidth = 100
+//
+//	[[routing]]
+//	name = "STUDIO-A"
+//	source = "CAM1 (Chan 1)"
+//
+//	[[links]]
+//	from = "cam1"
+//	to = "PROGRAM"
+//
+//Only the subset of TOML these files need is supported, see parseTOML.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/FlowingSPDG/ndi-go"
+)
+
+//LoadPipelineConfig parses and validates a pipeline configuration. Unknown keys are an error.
+func LoadPipelineConfig(r io.Reader) (*ndi.PipelineConfig, error) {
+	doc, err := parseTOML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	//The TOML document has the same shape as the JSON form, so reuse the JSON field names.
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+
+	var cfg ndi.PipelineConfig
+	if err := d.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
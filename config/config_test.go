@@ -0,0 +1,53 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FlowingSPDG/ndi-go"
+)
+
+func TestLoadPipelineConfig(t *testing.T) {
+	doc := `
+[[senders]]
+name = "PROGRAM"
+
+[[receivers]]
+name = "cam1"
+source = "CAM1 (Chan 1)"
+bandwidth = 0
+
+[[routing]]
+name = "STUDIO-A"
+source = "CAM1 (Chan 1)"
+
+[[links]]
+from = "cam1"
+to = "PROGRAM"
+`
+
+	cfg, err := LoadPipelineConfig(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Senders) != 1 || cfg.Senders[0].Name != "PROGRAM" {
+		t.Errorf("Unexpected senders %+v.", cfg.Senders)
+	}
+	if len(cfg.Receivers) != 1 || cfg.Receivers[0].Bandwidth != ndi.RecvBandwidthLowest {
+		t.Errorf("Unexpected receivers %+v.", cfg.Receivers)
+	}
+	if len(cfg.Routing) != 1 || cfg.Routing[0].Name != "STUDIO-A" || cfg.Routing[0].Source != "CAM1 (Chan 1)" {
+		t.Errorf("Unexpected routing %+v.", cfg.Routing)
+	}
+
+	if _, err := LoadPipelineConfig(strings.NewReader("[[senders]]\nnmae = \"typo\"")); err == nil {
+		t.Error("Expected an error for an unknown key.")
+	}
+	if _, err := LoadPipelineConfig(strings.NewReader("[[links]]\nfrom = \"x\"\nto = \"y\"")); err == nil {
+		t.Error("Expected an error for a link to unknown components.")
+	}
+}
@@ -0,0 +1,227 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tomlInteger = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlFloat   = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+)
+
+//parseTOML decodes the subset of TOML that pipeline files need: tables, arrays of tables, dotted table names
+//and single line key/value pairs holding strings, decimal integers, floats, booleans or arrays of those. A full TOML
+//library would be the only third-party dependency of the module, which every user of ndi would then pull in.
+func parseTOML(r io.Reader) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+
+	s := bufio.NewScanner(r)
+	for lineNo := 1; s.Scan(); lineNo++ {
+		line := strings.TrimSpace(stripComment(s.Text()))
+		if line == "" {
+			continue
+		}
+
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("line %d: %s", lineNo, fmt.Sprintf(format, args...))
+		}
+
+		switch {
+		case strings.HasPrefix(line, "[["):
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fail("unterminated table array header")
+			}
+			path := splitKey(line[2 : len(line)-2])
+			parent, err := walkTables(root, path[:len(path)-1])
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+
+			name := path[len(path)-1]
+			arr, ok := parent[name].([]interface{})
+			if !ok && parent[name] != nil {
+				return nil, fail("%s is not an array of tables", name)
+			}
+			current = make(map[string]interface{})
+			parent[name] = append(arr, current)
+
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fail("unterminated table header")
+			}
+			t, err := walkTables(root, splitKey(line[1:len(line)-1]))
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			current = t
+
+		default:
+			eq := strings.IndexByte(line, '=')
+			if eq < 0 {
+				return nil, fail("expected key = value")
+			}
+
+			key := unquoteKey(strings.TrimSpace(line[:eq]))
+			if key == "" {
+				return nil, fail("empty key")
+			}
+			if _, ok := current[key]; ok {
+				return nil, fail("duplicate key %s", key)
+			}
+
+			v, rest, err := parseValue(strings.TrimSpace(line[eq+1:]))
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			if strings.TrimSpace(rest) != "" {
+				return nil, fail("unexpected %q after value", rest)
+			}
+			current[key] = v
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+//Returns the table at path, creating missing tables. For an array of tables the last element is used.
+func walkTables(root map[string]interface{}, path []string) (map[string]interface{}, error) {
+	t := root
+	for _, name := range path {
+		switch v := t[name].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			t[name] = next
+			t = next
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			t = v[len(v)-1].(map[string]interface{})
+		default:
+			return nil, fmt.Errorf("%s is not a table", name)
+		}
+	}
+	return t, nil
+}
+
+func splitKey(s string) []string {
+	parts := strings.Split(s, ".")
+	for i, p := range parts {
+		parts[i] = unquoteKey(strings.TrimSpace(p))
+	}
+	return parts
+}
+
+func unquoteKey(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+//Removes a trailing comment, ignoring # inside strings.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+//Parses the value at the start of s and returns the remainder.
+func parseValue(s string) (interface{}, string, error) {
+	if s == "" {
+		return nil, "", fmt.Errorf("missing value")
+	}
+
+	switch s[0] {
+	case '"':
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				v, err := strconv.Unquote(s[:i+1])
+				return v, s[i+1:], err
+			}
+		}
+		return nil, "", fmt.Errorf("unterminated string")
+
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+
+	case '[':
+		var arr []interface{}
+		rest := strings.TrimSpace(s[1:])
+		for {
+			if strings.HasPrefix(rest, "]") {
+				return arr, rest[1:], nil
+			}
+
+			v, r, err := parseValue(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			arr = append(arr, v)
+
+			rest = strings.TrimSpace(r)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, "", fmt.Errorf("expected , or ] in array")
+			}
+		}
+	}
+
+	end := strings.IndexAny(s, ",]")
+	if end < 0 {
+		end = len(s)
+	}
+	word, rest := strings.TrimSpace(s[:end]), s[end:]
+
+	switch word {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+
+	//Only decimal numbers are supported. Leading zeros are rejected like TOML does, rather than read as octal.
+	clean := strings.ReplaceAll(word, "_", "")
+	if tomlInteger.MatchString(word) {
+		i, err := strconv.ParseInt(clean, 10, 64)
+		return i, rest, err
+	}
+	if tomlFloat.MatchString(word) {
+		f, err := strconv.ParseFloat(clean, 64)
+		return f, rest, err
+	}
+	return nil, "", fmt.Errorf("invalid value %q", word)
+}
@@ -0,0 +1,83 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	doc := `
+# A comment
+title = "pipe # line" # trailing comment
+check = true
+
+[name_vars]
+site = 'TYO'
+
+[[senders]]
+name = "PROGRAM"
+groups = "studio,backup"
+
+[[senders]]
+name_template = "{site}-CAM"
+
+[[receivers]]
+name = "cam1"
+bandwidth = -10
+ratio = 1.5
+ports = [1, 2, 3]
+big = 1_000
+zero = 0
+exp = -2e3
+`
+
+	v, err := parseTOML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"title":     "pipe # line",
+		"check":     true,
+		"name_vars": map[string]interface{}{"site": "TYO"},
+		"senders": []interface{}{
+			map[string]interface{}{"name": "PROGRAM", "groups": "studio,backup"},
+			map[string]interface{}{"name_template": "{site}-CAM"},
+		},
+		"receivers": []interface{}{
+			map[string]interface{}{"name": "cam1", "bandwidth": int64(-10), "ratio": 1.5, "ports": []interface{}{int64(1), int64(2), int64(3)},
+				"big": int64(1000), "zero": int64(0), "exp": -2000.0},
+		},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Expected %v but got %v.", expected, v)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	docs := []string{
+		"name",
+		"name = ",
+		"name = \"unterminated",
+		"name = 1\nname = 2",
+		"[senders\nname = 1",
+		"name = 1 2",
+		"ports = [1 2]",
+		"bandwidth = 010",
+		"bandwidth = 0x10",
+		"bandwidth = 1__0",
+		"ratio = 01.5",
+		"ratio = Inf",
+	}
+
+	for _, doc := range docs {
+		if _, err := parseTOML(strings.NewReader(doc)); err == nil {
+			t.Errorf("Expected an error for %q.", doc)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var connectTimeoutErr = errors.New("timed out connecting to source")
+
+//ConnectPolicy controls how ConnectWithPolicy decides that a receiver is connected.
+type ConnectPolicy struct {
+	//How long to keep trying in total. Zero means until the context is done.
+	Deadline time.Duration
+
+	//How long a single capture waits for a frame. Zero means one second.
+	AttemptTimeoutInMs uint32
+
+	//How many captures in a row must return a frame. Zero means one.
+	RequiredFrames int
+
+	//Whether a lost connection reconnects and starts counting again, rather than failing.
+	RetryOnError bool
+}
+
+const defaultConnectAttemptTimeoutInMs = 1000
+
+//The parts of RecvInstance that ConnectWithPolicy uses.
+type connectReceiver interface {
+	Connect(source *Source)
+	CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType
+	FreeVideoV2(vf *VideoFrameV2)
+	FreeAudioV2(af *AudioFrameV2)
+	FreeMetadataV2(mf *MetadataFrame)
+}
+
+//ConnectWithPolicy connects r to src and captures until policy.RequiredFrames captures in a row have returned a
+//frame. The frames are freed again. A capture that gets no frame within policy.AttemptTimeoutInMs starts the count
+//over. Returns ctx.Err() if ctx is done first and an error if the deadline passes or the connection is lost
+//without RetryOnError.
+func ConnectWithPolicy(ctx context.Context, r *RecvInstance, src Source, policy ConnectPolicy) error {
+	return connectWithPolicy(ctx, r, src, policy)
+}
+
+func connectWithPolicy(ctx context.Context, r connectReceiver, src Source, policy ConnectPolicy) error {
+	var deadline time.Time
+	if policy.Deadline > 0 {
+		deadline = sysClock.Now().Add(policy.Deadline)
+	}
+	timeout := policy.AttemptTimeoutInMs
+	if timeout == 0 {
+		timeout = defaultConnectAttemptTimeoutInMs
+	}
+	required := policy.RequiredFrames
+	if required < 1 {
+		required = 1
+	}
+
+	r.Connect(&src)
+
+	var (
+		vf VideoFrameV2
+		af AudioFrameV2
+		mf MetadataFrame
+	)
+	for frames := 0; frames < required; {
+		attempt := timeout
+		if !deadline.IsZero() {
+			left := deadline.Sub(sysClock.Now())
+			if left <= 0 {
+				return connectTimeoutErr
+			}
+			//The last attempt ends with the deadline.
+			if ms := (left + time.Millisecond - 1) / time.Millisecond; ms < time.Duration(attempt) {
+				attempt = uint32(ms)
+			}
+		}
+
+		ft, err := captureWithContext(ctx, attempt, func(timeoutInMs uint32) FrameType {
+			return r.CaptureV2(&vf, &af, &mf, timeoutInMs)
+		})
+		if err != nil {
+			return err
+		}
+
+		switch ft {
+		case FrameTypeVideo:
+			r.FreeVideoV2(&vf)
+			frames++
+		case FrameTypeAudio:
+			r.FreeAudioV2(&af)
+			frames++
+		case FrameTypeMetadata:
+			r.FreeMetadataV2(&mf)
+			frames++
+		case FrameTypeNone:
+			frames = 0
+		case FrameTypeError:
+			if !policy.RetryOnError {
+				return connectionLostErr
+			}
+			r.Connect(&src)
+			frames = 0
+		}
+	}
+	return nil
+}
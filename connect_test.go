@@ -0,0 +1,88 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//Plays back a scripted sequence of capture results and idles once it runs out. FrameTypeNone in the script is a
+//sender that goes quiet for a second, captures during that time wait out their timeout on sysClock.
+type scriptedReceiver struct {
+	script     []FrameType
+	connects   int
+	freed      int
+	quietUntil time.Time
+}
+
+func (r *scriptedReceiver) Connect(*Source) {
+	r.connects++
+}
+
+func (r *scriptedReceiver) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	if len(r.script) != 0 && r.script[0] == FrameTypeNone {
+		r.script = r.script[1:]
+		r.quietUntil = sysClock.Now().Add(time.Second)
+	}
+	if len(r.script) == 0 || sysClock.Now().Before(r.quietUntil) {
+		sysClock.Sleep(time.Duration(timeoutInMs) * time.Millisecond)
+		return FrameTypeNone
+	}
+
+	ft := r.script[0]
+	r.script = r.script[1:]
+	return ft
+}
+
+func (r *scriptedReceiver) FreeVideoV2(*VideoFrameV2)     { r.freed++ }
+func (r *scriptedReceiver) FreeAudioV2(*AudioFrameV2)     { r.freed++ }
+func (r *scriptedReceiver) FreeMetadataV2(*MetadataFrame) { r.freed++ }
+
+func TestConnectWithPolicy(t *testing.T) {
+	clock := useFakeClock(t)
+
+	//A sender that restarts twice before it delivers steadily.
+	flapping := []FrameType{FrameTypeVideo, FrameTypeError, FrameTypeAudio, FrameTypeVideo, FrameTypeError, FrameTypeVideo, FrameTypeMetadata, FrameTypeVideo}
+	gap := []FrameType{FrameTypeVideo, FrameTypeNone, FrameTypeVideo}
+
+	tests := []struct {
+		name     string
+		script   []FrameType
+		policy   ConnectPolicy
+		err      error
+		connects int
+	}{
+		{"retry", flapping, ConnectPolicy{Deadline: time.Second, RequiredFrames: 3, RetryOnError: true}, nil, 3},
+		{"fail", flapping, ConnectPolicy{Deadline: time.Second, RequiredFrames: 3}, connectionLostErr, 1},
+		//The quiet second times the attempt out, so the frame before it does not count.
+		{"gap", gap, ConnectPolicy{Deadline: 10 * time.Second, AttemptTimeoutInMs: 200, RequiredFrames: 2}, connectTimeoutErr, 1},
+		{"gap then steady", append(gap, FrameTypeVideo), ConnectPolicy{Deadline: 10 * time.Second, AttemptTimeoutInMs: 200, RequiredFrames: 2}, nil, 1},
+		//An attempt that outlasts the quiet second keeps the count.
+		{"slow", gap, ConnectPolicy{Deadline: 10 * time.Second, AttemptTimeoutInMs: 2000, RequiredFrames: 2}, nil, 1},
+		{"timeout", []FrameType{FrameTypeVideo}, ConnectPolicy{Deadline: 100 * time.Millisecond, AttemptTimeoutInMs: 30, RequiredFrames: 2}, connectTimeoutErr, 1},
+	}
+
+	for _, test := range tests {
+		r := &scriptedReceiver{script: append([]FrameType(nil), test.script...)}
+		start := clock.Now()
+		if err := connectWithPolicy(context.Background(), r, Source{}, test.policy); err != test.err {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.err, err)
+		}
+		if r.connects != test.connects {
+			t.Errorf("%s: Expected %d connects but got %d.", test.name, test.connects, r.connects)
+		}
+		if elapsed := clock.Now().Sub(start); test.err == connectTimeoutErr && elapsed != test.policy.Deadline {
+			t.Errorf("%s: Expected to give up after %v but took %v.", test.name, test.policy.Deadline, elapsed)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := connectWithPolicy(ctx, &scriptedReceiver{}, Source{}, ConnectPolicy{}); err != context.Canceled {
+		t.Errorf("Expected %v but got %v.", context.Canceled, err)
+	}
+}
@@ -0,0 +1,124 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"math"
+)
+
+var invalidBlurRadiusErr = errors.New("blur radius must not be negative")
+
+//DepthOfFieldBlur simulates a shallow depth of field by blurring vf with a Gaussian whose radius grows linearly
+//from zero at row focusY to blurRadius at the row farthest away from it. BGRA and BGRX frames are supported.
+//The returned frame owns its data and carries no metadata.
+func DepthOfFieldBlur(vf *VideoFrameV2, focusY int32, blurRadius int) (*VideoFrameV2, error) {
+	if vf == nil || vf.Xres <= 0 || vf.Yres <= 0 {
+		return nil, invalidVideoFrameErr
+	}
+	if vf.FourCC != FourCCTypeBGRA && vf.FourCC != FourCCTypeBGRX {
+		return nil, unsupportedFourCCErr
+	}
+	if blurRadius < 0 {
+		return nil, invalidBlurRadiusErr
+	}
+
+	width, height := int(vf.Xres), int(vf.Yres)
+	srcData := vf.data()
+	if srcData == nil || int(vf.LineStride) < width*4 {
+		return nil, invalidVideoFrameErr
+	}
+
+	//The radius of every row, relative to the distance of the farthest row from focus.
+	focus := int(focusY)
+	farthest := focus
+	if height-1-focus > farthest {
+		farthest = height - 1 - focus
+	}
+	radii := make([]int, height)
+	kernels := make(map[int][]float32)
+	for y := range radii {
+		if farthest > 0 {
+			dist := y - focus
+			if dist < 0 {
+				dist = -dist
+			}
+			radii[y] = int(math.Round(float64(blurRadius) * float64(dist) / float64(farthest)))
+		}
+		if _, ok := kernels[radii[y]]; !ok {
+			kernels[radii[y]] = gaussianKernel(radii[y])
+		}
+	}
+
+	//Horizontal pass into an intermediate buffer followed by a vertical pass, both with the radius of the
+	//output row.
+	stride := width * 4
+	tmp := make([]float32, stride*height)
+	for y := 0; y < height; y++ {
+		r, kernel := radii[y], kernels[radii[y]]
+		src := srcData[y*int(vf.LineStride):]
+		dst := tmp[y*stride:]
+		for x := 0; x < width; x++ {
+			var acc [4]float32
+			for k, w := range kernel {
+				sx := clampInt(x+k-r, 0, width-1) * 4
+				for c := range acc {
+					acc[c] += w * float32(src[sx+c])
+				}
+			}
+			copy(dst[x*4:], acc[:])
+		}
+	}
+
+	out := make([]byte, stride*height)
+	for y := 0; y < height; y++ {
+		r, kernel := radii[y], kernels[radii[y]]
+		dst := out[y*stride:]
+		for i := 0; i < stride; i++ {
+			var acc float32
+			for k, w := range kernel {
+				acc += w * tmp[clampInt(y+k-r, 0, height-1)*stride+i]
+			}
+			dst[i] = clampByte(acc)
+		}
+	}
+
+	ret := *vf
+	ret.LineStride = int32(stride)
+	ret.Data = &out[0]
+	ret.Metadata = nil
+	return &ret, nil
+}
+
+//Returns the normalized weights of a Gaussian covering -radius..radius, with sigma at half the radius.
+func gaussianKernel(radius int) []float32 {
+	if radius == 0 {
+		return []float32{1}
+	}
+
+	sigma := float64(radius) / 2
+	kernel := make([]float32, 2*radius+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		w := math.Exp(-d * d / (2 * sigma * sigma))
+		kernel[i] = float32(w)
+		sum += w
+	}
+	for i := range kernel {
+		kernel[i] /= float32(sum)
+	}
+	return kernel
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
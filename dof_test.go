@@ -0,0 +1,44 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestDepthOfFieldBlur(t *testing.T) {
+	//Alternating black and white columns, which any blur pulls towards gray.
+	src, _ := newTestVideoFrame(FourCCTypeBGRA, 8, 9, 4, func(x, y int) byte {
+		if x%2 == 0 {
+			return 255
+		}
+		return 0
+	})
+
+	out, err := DepthOfFieldBlur(src, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Contrast between two neighbouring pixels of a row.
+	contrast := func(data []byte, y int) int {
+		return int(data[y*32]) - int(data[y*32+4])
+	}
+
+	data := out.data()
+	if c := contrast(data, 4); c != 255 {
+		t.Errorf("Expected the row in focus to stay sharp but got a contrast of %d.", c)
+	}
+	for _, y := range []int{0, 8} {
+		if c := contrast(data, y); c < -64 || c > 64 {
+			t.Errorf("Expected row %d to be blurred but got a contrast of %d.", y, c)
+		}
+	}
+	if near, far := contrast(data, 5), contrast(data, 7); near <= far {
+		t.Errorf("Expected the blur to grow away from focus but got a contrast of %d near and %d far.", near, far)
+	}
+
+	if _, err := DepthOfFieldBlur(src, 4, -1); err != invalidBlurRadiusErr {
+		t.Errorf("Expected %v but got %v.", invalidBlurRadiusErr, err)
+	}
+}
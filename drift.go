@@ -0,0 +1,120 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	//The largest correction, in either direction. Far beyond the drift of real clocks and still inaudible.
+	maxDriftCorrection = 0.001
+
+	//Gains of the controller, for a depth error in seconds. They give a critically damped response that
+	//settles in a few minutes, slow enough to ride out network jitter.
+	driftProportionalGain = 0.02
+	driftIntegralGain     = 0.0001
+
+	//How long the buffer depth is averaged over, to smooth out the bursts in which audio arrives.
+	driftSmoothing = time.Second
+)
+
+//DriftCompensator keeps a playout buffer at its target depth when the sender's clock and the local audio clock
+//run at slightly different rates. It watches the long-term trend of the buffer depth and resamples the audio
+//taken from the buffer by up to 0.1%. It is not safe for concurrent use.
+type DriftCompensator struct {
+	clock      clock
+	sampleRate float64
+	target     float64
+
+	last     time.Time
+	depth    float64 //Smoothed depth error, in seconds.
+	integral float64
+	ratio    float64
+
+	//Fraction of an input sample that is owed to the next read.
+	owed float64
+}
+
+//NewDriftCompensator returns a compensator for a buffer of audio at sampleRate that should hold targetDepth samples.
+func NewDriftCompensator(sampleRate, targetDepth int) *DriftCompensator {
+	return &DriftCompensator{
+		clock:      sysClock,
+		sampleRate: float64(sampleRate),
+		target:     float64(targetDepth),
+		ratio:      1,
+	}
+}
+
+//Update records the current depth of the buffer in samples. Call it every time audio is taken from the buffer.
+func (c *DriftCompensator) Update(depth int) {
+	now := c.clock.Now()
+	errSec := (float64(depth) - c.target) / c.sampleRate
+	if c.last.IsZero() {
+		c.last, c.depth = now, errSec
+		return
+	}
+	dt := now.Sub(c.last).Seconds()
+	c.last = now
+	if dt <= 0 {
+		return
+	}
+
+	c.depth += (errSec - c.depth) * math.Min(dt/driftSmoothing.Seconds(), 1)
+
+	//Stop integrating while the correction is saturated, so that it recovers quickly once it no longer is.
+	correction := driftProportionalGain*c.depth + driftIntegralGain*c.integral
+	if math.Abs(correction) < maxDriftCorrection || correction*c.depth < 0 {
+		c.integral += c.depth * dt
+		correction = driftProportionalGain*c.depth + driftIntegralGain*c.integral
+	}
+	c.ratio = 1 + math.Max(-maxDriftCorrection, math.Min(maxDriftCorrection, correction))
+}
+
+//Correction returns the current ratio of input to output samples, above 1 when the buffer is drained faster
+//than real time because the sender runs fast.
+func (c *DriftCompensator) Correction() float64 {
+	return c.ratio
+}
+
+//InputSamples returns how many samples to take from the buffer to produce outputSamples samples of playout.
+func (c *DriftCompensator) InputSamples(outputSamples int) int {
+	exact := float64(outputSamples)*c.ratio + c.owed
+	n := math.Floor(exact)
+	c.owed = exact - n
+	return int(n)
+}
+
+//Resample stretches each channel of in to outputSamples samples by linear interpolation. Use it on the samples
+//taken from the buffer as told by InputSamples.
+func (c *DriftCompensator) Resample(in [][]float32, outputSamples int) [][]float32 {
+	out := make([][]float32, len(in))
+	for ch, samples := range in {
+		out[ch] = make([]float32, outputSamples)
+		if len(samples) == 0 {
+			continue
+		}
+		if len(samples) == 1 || outputSamples == 1 {
+			for i := range out[ch] {
+				out[ch][i] = samples[0]
+			}
+			continue
+		}
+
+		step := float64(len(samples)-1) / float64(outputSamples-1)
+		for i := range out[ch] {
+			pos := float64(i) * step
+			j := int(pos)
+			if j >= len(samples)-1 {
+				out[ch][i] = samples[len(samples)-1]
+				continue
+			}
+			frac := float32(pos - float64(j))
+			out[ch][i] = samples[j] + (samples[j+1]-samples[j])*frac
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDriftCompensator(t *testing.T) {
+	const (
+		sampleRate = 48000
+		target     = 4800
+		block      = 480 //10ms of playout
+		driftPPM   = 100
+	)
+
+	clock := useFakeClock(t)
+	c := NewDriftCompensator(sampleRate, target)
+
+	//The sender delivers its audio in bursts of 1024 samples, 100ppm faster than the local clock plays.
+	var produced, pending float64
+	consumed := 0
+	produced = target
+	for tick := 0; tick < 2*60*60*100; tick++ {
+		clock.Advance(10 * time.Millisecond)
+		pending += block * (1 + driftPPM*1e-6)
+		for pending >= 1024 {
+			produced += 1024
+			pending -= 1024
+		}
+
+		consumed += c.InputSamples(block)
+		depth := int(produced) - consumed
+		c.Update(depth)
+
+		if tick > 60*100 && math.Abs(float64(depth-target)) > 1500 {
+			t.Fatalf("Buffer left its target after %v: %d samples.", time.Duration(tick)*10*time.Millisecond, depth)
+		}
+	}
+
+	if got := c.Correction(); math.Abs(got-(1+driftPPM*1e-6)) > 10e-6 {
+		t.Errorf("Expected a correction of about %v but got %v.", 1+driftPPM*1e-6, got)
+	}
+}
+
+func TestDriftResample(t *testing.T) {
+	c := NewDriftCompensator(48000, 0)
+	out := c.Resample([][]float32{{0, 1, 2, 3, 4}}, 9)
+	for i, s := range out[0] {
+		if s != float32(i)/2 {
+			t.Errorf("Expected sample %d to be %v but got %v.", i, float32(i)/2, s)
+		}
+	}
+}
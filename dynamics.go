@@ -0,0 +1,160 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"math"
+)
+
+var invalidAudioFrameErr = errors.New("invalid audio frame")
+
+//DynamicsProcessor limits the dynamic range of planar float audio frames in-place.
+//The envelope is carried over between frames, so one processor must be used per stream.
+type DynamicsProcessor struct {
+	threshold           float64
+	attackMs, releaseMs float64
+	envelope            float64
+}
+
+//NewLimiter returns a peak limiter that keeps the signal below thresholdDBFS. The envelope follows rising
+//peaks with a time constant of attackMs and decays with a time constant of releaseMs.
+func NewLimiter(thresholdDBFS float64, attackMs, releaseMs float64) *DynamicsProcessor {
+	return &DynamicsProcessor{
+		threshold: math.Pow(10, thresholdDBFS/20),
+		attackMs:  attackMs,
+		releaseMs: releaseMs,
+	}
+}
+
+func timeConstantCoeff(ms float64, sampleRate int32) float64 {
+	if ms <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (ms / 1000 * float64(sampleRate)))
+}
+
+//Process applies the limiter to all channels of af. The gain is linked across channels so the stereo image is kept.
+func (dp *DynamicsProcessor) Process(af *AudioFrameV2) error {
+	if af == nil || af.SampleRate <= 0 || af.NumChannels < 0 || af.NumSamples < 0 {
+		return invalidAudioFrameErr
+	}
+	if af.NumChannels == 0 || af.NumSamples == 0 {
+		return nil
+	}
+	if af.Data == nil || int(af.ChannelStride) < int(af.NumSamples)*4 {
+		return invalidAudioFrameErr
+	}
+
+	attack := timeConstantCoeff(dp.attackMs, af.SampleRate)
+	release := timeConstantCoeff(dp.releaseMs, af.SampleRate)
+
+	channels := make([][]float32, af.NumChannels)
+	for ch := range channels {
+		channels[ch] = af.ReadChannel(ch)
+	}
+
+	for i := 0; i < int(af.NumSamples); i++ {
+		var peak float64
+		for _, samples := range channels {
+			if v := math.Abs(float64(samples[i])); v > peak {
+				peak = v
+			}
+		}
+
+		if peak > dp.envelope {
+			dp.envelope = attack*dp.envelope + (1-attack)*peak
+		} else {
+			dp.envelope = release*dp.envelope + (1-release)*peak
+		}
+
+		gain := 1.0
+		if dp.envelope > dp.threshold {
+			gain = dp.threshold / dp.envelope
+		}
+
+		for _, samples := range channels {
+			v := float64(samples[i]) * gain
+
+			//The envelope lags behind fast transients, clamp whatever it lets through.
+			if v > dp.threshold {
+				v = dp.threshold
+			} else if v < -dp.threshold {
+				v = -dp.threshold
+			}
+			samples[i] = float32(v)
+		}
+	}
+	return nil
+}
+
+//NoiseGate silences planar float audio frames in-place while they stay below a threshold, each channel on its own.
+//The gate state is carried over between frames, so one gate must be used per stream.
+type NoiseGate struct {
+	threshold                   float64
+	attackMs, holdMs, releaseMs float64
+	gains                       []float64
+	holds                       []int
+}
+
+//NewNoiseGate returns a gate that opens as soon as a sample reaches thresholdDBFS, ramping up linearly over
+//attackMs. After the last sample above the threshold it stays open for holdMs, which should be longer than half
+//the period of the lowest frequency to keep, and then closes linearly over releaseMs.
+func NewNoiseGate(thresholdDBFS, attackMs, holdMs, releaseMs float64) *NoiseGate {
+	return &NoiseGate{
+		threshold: math.Pow(10, thresholdDBFS/20),
+		attackMs:  attackMs,
+		holdMs:    holdMs,
+		releaseMs: releaseMs,
+	}
+}
+
+//Returns the gain change per sample of a linear ramp over ms, 1 for an instant change.
+func rampStep(ms float64, sampleRate int32) float64 {
+	if n := ms / 1000 * float64(sampleRate); n > 1 {
+		return 1 / n
+	}
+	return 1
+}
+
+//Process applies the gate to all channels of af. A change of the channel count resets the gate to closed.
+func (g *NoiseGate) Process(af *AudioFrameV2) error {
+	if af == nil || af.SampleRate <= 0 || af.NumChannels < 0 || af.NumSamples < 0 {
+		return invalidAudioFrameErr
+	}
+	if af.NumChannels == 0 || af.NumSamples == 0 {
+		return nil
+	}
+	if af.Data == nil || int(af.ChannelStride) < int(af.NumSamples)*4 {
+		return invalidAudioFrameErr
+	}
+
+	if len(g.gains) != int(af.NumChannels) {
+		g.gains = make([]float64, af.NumChannels)
+		g.holds = make([]int, af.NumChannels)
+	}
+	attack := rampStep(g.attackMs, af.SampleRate)
+	release := rampStep(g.releaseMs, af.SampleRate)
+	hold := int(g.holdMs / 1000 * float64(af.SampleRate))
+
+	for ch := range g.gains {
+		gain, held := g.gains[ch], g.holds[ch]
+		samples := af.ReadChannel(ch)
+		for i, v := range samples {
+			if math.Abs(float64(v)) >= g.threshold {
+				held = hold
+				gain = math.Min(1, gain+attack)
+			} else if held > 0 {
+				held--
+				gain = math.Min(1, gain+attack)
+			} else {
+				gain = math.Max(0, gain-release)
+			}
+			samples[i] = float32(float64(v) * gain)
+		}
+		g.gains[ch], g.holds[ch] = gain, held
+	}
+	return nil
+}
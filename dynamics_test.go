@@ -0,0 +1,101 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLimiter(t *testing.T) {
+	const (
+		numChannels = 2
+		numSamples  = 4800
+	)
+
+	data := make([]float32, numChannels*numSamples)
+	for i := 0; i < numSamples; i++ {
+		v := float32(1.5 * math.Sin(2*math.Pi*1000*float64(i)/48000))
+		data[i] = v
+		data[numSamples+i] = v / 2
+	}
+
+	af := NewAudioFrameV2()
+	af.NumChannels = numChannels
+	af.NumSamples = numSamples
+	af.ChannelStride = numSamples * 4
+	af.Data = &data[0]
+
+	limiter := NewLimiter(-6, 1, 50)
+	if err := limiter.Process(af); err != nil {
+		t.Fatal(err)
+	}
+
+	threshold := float32(math.Pow(10, -6.0/20))
+	for i, v := range data {
+		if v > threshold || v < -threshold {
+			t.Fatalf("Sample %d is %f which exceeds the threshold of %f.", i, v, threshold)
+		}
+	}
+
+	if err := limiter.Process(&AudioFrameV2{SampleRate: 48000, NumChannels: 2, NumSamples: 16}); err == nil {
+		t.Error("Expected an error for a frame without data.")
+	}
+}
+
+func TestNoiseGate(t *testing.T) {
+	const numSamples = 4800
+
+	//A loud 1kHz tone on the first channel that stops halfway, quiet noise at -60dBFS everywhere else.
+	noise := func(i int) float32 { return float32(0.001 * float64(1-i%2*2)) }
+	loud, quiet := make([]float32, numSamples), make([]float32, numSamples)
+	for i := range loud {
+		loud[i], quiet[i] = noise(i), noise(i)
+		if i < numSamples/2 {
+			loud[i] = float32(0.5 * math.Sin(2*math.Pi*1000*float64(i)/48000))
+		}
+	}
+	af := newPlanarAudioFrame([][]float32{loud, quiet}, 48000)
+	in := append([]float32(nil), af.ReadSamples()...)
+
+	gate := NewNoiseGate(-40, 1, 10, 10)
+	if err := gate.Process(af); err != nil {
+		t.Fatal(err)
+	}
+
+	out := af.ReadChannel(0)
+	//Open after the 48 samples of the attack, held for 480 samples after the tone and closed 480 samples later.
+	for _, i := range []int{60, 1000, 2399, 2500, 2879} {
+		if out[i] != in[i] {
+			t.Errorf("Expected sample %d to pass the open gate but got %f instead of %f.", i, out[i], in[i])
+		}
+	}
+	if out[3000] == 0 || math.Abs(float64(out[3000])) >= 0.001 {
+		t.Errorf("Expected sample 3000 to be attenuated by the release but got %f.", out[3000])
+	}
+	for i := 3360; i < numSamples; i++ {
+		if out[i] != 0 {
+			t.Fatalf("Expected the gate to be closed at sample %d but got %f.", i, out[i])
+		}
+	}
+	for i, v := range af.ReadChannel(1) {
+		if v != 0 {
+			t.Fatalf("Expected the quiet channel to stay silent but sample %d is %f.", i, v)
+		}
+	}
+
+	//The state carries over, the tone starts against the closed gate.
+	next := newPlanarAudioFrame([][]float32{{0.5, 0.5}, {0, 0}}, 48000)
+	if err := gate.Process(next); err != nil {
+		t.Fatal(err)
+	}
+	if v := next.ReadChannel(0)[0]; v != float32(0.5/48.0) {
+		t.Errorf("Expected the attack to start from silence but got %f.", v)
+	}
+
+	if err := gate.Process(&AudioFrameV2{SampleRate: 48000, NumChannels: 2, NumSamples: 16}); err != invalidAudioFrameErr {
+		t.Errorf("Expected %v but got %v.", invalidAudioFrameErr, err)
+	}
+}
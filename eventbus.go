@@ -0,0 +1,97 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//Event is implemented by the typed events that helpers publish on an EventBus.
+type Event interface {
+	//A short name of the kind of event, for logging.
+	EventKind() string
+}
+
+func (MuteEvent) EventKind() string         { return "mute" }
+func (QualityEvent) EventKind() string      { return "quality" }
+func (SourceEvent) EventKind() string       { return "sources" }
+func (FormatChange) EventKind() string      { return "video_format" }
+func (AudioFormatChange) EventKind() string { return "audio_format" }
+func (FailoverEvent) EventKind() string     { return "failover" }
+
+//AncillaryLostEvent reports the ancillary data of a frame that was dropped, see AncillaryCarrier.
+type AncillaryLostEvent struct {
+	Ancillary []Ancillary
+}
+
+func (AncillaryLostEvent) EventKind() string { return "ancillary_lost" }
+
+//EventBus fans events out to any number of subscribers, for central logging and monitoring. Helpers publish to
+//it when it is set in their Bus field, in addition to their own callbacks. Events that helpers deliver on
+//channels, like QualityEvent and SourceEvent, can be published as they are received. Publishing never blocks: a subscriber
+//that does not keep up misses events, which are counted in Dropped. The zero value is ready to use.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	dropped     int64
+}
+
+//Subscribe returns a channel receiving every event published from now on, buffering up to buffer events, and a
+//function that ends the subscription and closes the channel.
+func (b *EventBus) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan Event]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+//Publish hands ev to every subscriber that has room for it. Publishing on a nil bus does nothing, so helpers
+//can publish unconditionally.
+func (b *EventBus) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			atomic.AddInt64(&b.dropped, 1)
+		}
+	}
+}
+
+//Dropped returns how many events subscribers have missed so far.
+func (b *EventBus) Dropped() int64 {
+	return atomic.LoadInt64(&b.dropped)
+}
+
+//Log subscribes logf, which may be log.Printf, to the bus until the returned function is called.
+func (b *EventBus) Log(logf func(format string, v ...interface{})) func() {
+	ch, stop := b.Subscribe(64)
+	go func() {
+		for ev := range ch {
+			logf("ndi: %s event: %+v", ev.EventKind(), ev)
+		}
+	}()
+	return stop
+}
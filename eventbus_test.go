@@ -0,0 +1,84 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	var bus EventBus
+	events, unsubscribe := bus.Subscribe(16)
+
+	var (
+		mu   sync.Mutex
+		logs []string
+	)
+	logged := make(chan struct{}, 16)
+	stopLog := bus.Log(func(format string, v ...interface{}) {
+		mu.Lock()
+		logs = append(logs, fmt.Sprintf(format, v...))
+		mu.Unlock()
+		logged <- struct{}{}
+	})
+
+	//A session: the sender mutes and unmutes, the receiver sees the format change and a frame is dropped.
+	mute := NewMuteController()
+	mute.Bus = &bus
+	videoTracker := FormatTracker{Bus: &bus}
+	audioTracker := AudioFormatTracker{Bus: &bus}
+	carrier := AncillaryCarrier{Policy: AncillaryReportLost, Bus: &bus}
+
+	vf := NewVideoFrameV2()
+	vf.Xres, vf.Yres = 1920, 1080
+	videoTracker.Update(vf)
+	af := NewAudioFrameV2()
+	af.SampleRate, af.NumChannels = 48000, 2
+	audioTracker.Update(af)
+
+	mute.SetVideoMuted(true)
+	mute.SetVideoMuted(false)
+	oldVideo := vf.Format()
+	vf.Xres, vf.Yres = 1280, 720
+	videoTracker.Update(vf)
+	af.NumChannels = 8
+	audioTracker.Update(af)
+	carrier.Dropped([]Ancillary{{Type: "cc"}})
+	unsubscribe()
+
+	expected := []Event{
+		MuteEvent{VideoMuted: true},
+		MuteEvent{},
+		FormatChange{oldVideo, vf.Format()},
+		AudioFormatChange{AudioFormat{48000, 2}, AudioFormat{48000, 8}},
+		AncillaryLostEvent{[]Ancillary{{Type: "cc"}}},
+	}
+	var got []Event
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v but got %v.", expected, got)
+	}
+
+	for range expected {
+		<-logged
+	}
+	stopLog()
+	if len(logs) != len(expected) || logs[0] != "ndi: mute event: {VideoMuted:true AudioMuted:false}" {
+		t.Errorf("Expected %d log lines starting with the mute event but got %q.", len(expected), logs)
+	}
+
+	//A subscriber without room misses events instead of blocking the publisher.
+	_, unsubscribe = bus.Subscribe(0)
+	defer unsubscribe()
+	bus.Publish(MuteEvent{})
+	if dropped := bus.Dropped(); dropped != 1 {
+		t.Errorf("Expected 1 dropped event but got %d.", dropped)
+	}
+}
@@ -0,0 +1,82 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package main
+
+import (
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"github.com/FlowingSPDG/ndi-go"
+)
+
+const (
+	ndiLibName    = "Processing.NDI.Lib.x64.dll"
+	scanTimeout   = 5000
+	routeInterval = 10 * time.Second
+)
+
+func initializeNDI() {
+	libDir := os.Getenv("NDI_RUNTIME_DIR_V5")
+	if libDir == "" {
+		log.Fatalln("ndi sdk is not installed")
+	}
+
+	if err := ndi.LoadAndInitialize(path.Join(libDir, ndiLibName)); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func main() {
+	initializeNDI()
+
+	router := ndi.NewRoutingInstanceFromSettings(&ndi.RoutingSettings{NdiName: "Router Output 1"})
+	if router == nil {
+		log.Fatalln("could not create router")
+	}
+
+	pool := ndi.NewObjectPool()
+	finder := ndi.NewFindInstanceV2(pool.NewFindCreateSettings(true, "", ""))
+	if finder == nil {
+		log.Fatalln("could not create finder")
+	}
+
+	defer func() {
+		finder.Destroy()
+		router.Destroy()
+		ndi.DestroyAndUnload()
+	}()
+
+	output := router.GetSourceName()
+	log.Printf("Routing as %q, connect a receiver to it to follow the changes.", output.Name())
+
+	//Cycle through the discovered sources. Connected receivers are redirected without reconnecting to the router.
+	for i := 0; ; i++ {
+		finder.WaitForSources(scanTimeout)
+
+		var sources []*ndi.Source
+		for _, source := range finder.GetCurrentSources() {
+			if source.Name() != output.Name() {
+				sources = append(sources, source)
+			}
+		}
+		if len(sources) == 0 {
+			log.Println("No sources found, clearing the route.")
+			if err := router.Clear(); err != nil {
+				log.Println(err)
+			}
+			continue
+		}
+
+		source := sources[i%len(sources)]
+		if err := router.Change(source); err != nil {
+			log.Printf("Could not route to %q: %v", source.Name(), err)
+		} else {
+			log.Printf("Routed to %q, %d receiver(s) connected.", source.Name(), router.GetNoConnections(0))
+		}
+		time.Sleep(routeInterval)
+	}
+}
@@ -0,0 +1,82 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/FlowingSPDG/ndi-go"
+)
+
+const (
+	ndiLibName   = "Processing.NDI.Lib.x64.dll"
+	tallyTimeout = 1000
+)
+
+func initializeNDI() {
+	libDir := os.Getenv("NDI_RUNTIME_DIR_V5")
+	if libDir == "" {
+		log.Fatalln("ndi sdk is not installed")
+	}
+
+	if err := ndi.LoadAndInitialize(path.Join(libDir, ndiLibName)); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+//Stands in for a tally light, like a GPIO driven LED or a USB busylight. Red means on program, green on preview.
+type tallyLED struct {
+	color string
+}
+
+func (l *tallyLED) set(tally ndi.Tally) {
+	color := "off"
+	switch {
+	case tally.OnProgram:
+		color = "red"
+	case tally.OnPreview:
+		color = "green"
+	}
+
+	if color != l.color {
+		l.color = color
+		fmt.Printf("LED %s (program: %v, preview: %v)\n", color, tally.OnProgram, tally.OnPreview)
+	}
+}
+
+func main() {
+	initializeNDI()
+
+	pool := ndi.NewObjectPool()
+	settings := pool.NewSendCreateSettings("ndi-go tally", "", true, false)
+	inst := ndi.NewSendInstance(settings)
+	if inst == nil {
+		log.Fatalln("could not create sender")
+	}
+
+	defer func() {
+		inst.Destroy()
+		ndi.DestroyAndUnload()
+	}()
+
+	fmt.Println("Put \"ndi-go tally\" on program or preview, for instance in Studio Monitor...")
+
+	//GetTally only returns once the tally changes or the timeout passes, so the loop does not spin.
+	led := &tallyLED{}
+	led.set(ndi.Tally{})
+	for {
+		tally, changed, err := inst.GetTally(tallyTimeout)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if changed {
+			led.set(tally)
+		}
+	}
+}
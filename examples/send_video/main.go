@@ -51,7 +51,8 @@ func main() {
 		ndi.DestroyAndUnload()
 	}()
 
-	log.Println("Streaming video...")
+	source := inst.GetSourceName()
+	log.Printf("Streaming video as %q at %s...", source.Name(), source.Address())
 
 	for {
 		if _, err := rand.Read(frameData); err != nil {
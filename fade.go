@@ -0,0 +1,51 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "errors"
+
+var invalidFadeDurationErr = errors.New("fade is longer than the audio frame")
+
+//FadeIn ramps the first durationSamples of every channel of af linearly up from silence, in-place. The ramp is
+//mirrored by FadeOut, so a fade out followed by a fade in of the same length is symmetric. Zero does nothing.
+func FadeIn(af *AudioFrameV2, durationSamples int) error {
+	return fade(af, durationSamples, false)
+}
+
+//FadeOut ramps the last durationSamples of every channel of af linearly down to silence, in-place. The last
+//sample is silent.
+func FadeOut(af *AudioFrameV2, durationSamples int) error {
+	return fade(af, durationSamples, true)
+}
+
+func fade(af *AudioFrameV2, durationSamples int, out bool) error {
+	if af == nil || af.NumChannels < 0 || af.NumSamples < 0 {
+		return invalidAudioFrameErr
+	}
+	if durationSamples < 0 || durationSamples > int(af.NumSamples) {
+		return invalidFadeDurationErr
+	}
+	if durationSamples == 0 || af.NumChannels == 0 {
+		return nil
+	}
+	if af.Data == nil || int(af.ChannelStride) < int(af.NumSamples)*4 {
+		return invalidAudioFrameErr
+	}
+
+	for ch := 0; ch < int(af.NumChannels); ch++ {
+		samples := af.ReadChannel(ch)
+		if out {
+			samples = samples[len(samples)-durationSamples:]
+		}
+		for i := range samples[:durationSamples] {
+			gain := float32(i) / float32(durationSamples)
+			if out {
+				gain = float32(durationSamples-1-i) / float32(durationSamples)
+			}
+			samples[i] *= gain
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFade(t *testing.T) {
+	ones := func() []float32 { return []float32{1, 1, 1, 1, 1, 1} }
+	af := newPlanarAudioFrame([][]float32{ones(), ones()}, 48000)
+
+	if err := FadeIn(af, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := FadeOut(af, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := []float32{0, 0.25, 0.5, 0.75, 0.5, 0}
+	for ch := 0; ch < 2; ch++ {
+		if got := af.ReadChannel(ch); !reflect.DeepEqual(got, want) {
+			t.Errorf("Channel %d: Expected %v but got %v.", ch, want, got)
+		}
+	}
+
+	//Fading over the whole frame.
+	full := newPlanarAudioFrame([][]float32{ones()}, 48000)
+	if err := FadeOut(full, 6); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := full.ReadChannel(0)[0], float32(5)/6; got != want {
+		t.Errorf("Expected the fade out to start at %v but got %v.", want, got)
+	}
+
+	tests := []struct {
+		name     string
+		af       *AudioFrameV2
+		duration int
+		err      error
+	}{
+		{"nil", nil, 1, invalidAudioFrameErr},
+		{"too long", af, 7, invalidFadeDurationErr},
+		{"negative", af, -1, invalidFadeDurationErr},
+		{"no data", &AudioFrameV2{NumChannels: 2, NumSamples: 6}, 1, invalidAudioFrameErr},
+	}
+	for _, test := range tests {
+		if err := FadeIn(test.af, test.duration); err != test.err {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "time"
+
+const (
+	defaultFailoverDelay = 2 * time.Second
+	defaultRestoreDelay  = 5 * time.Second
+)
+
+type FailoverOptions struct {
+	//How long the primary source may deliver no audio or video before the backup is switched to. Zero means 2s.
+	FailoverDelay time.Duration
+
+	//How long the primary source must be back before it is switched to again. Zero means 5s.
+	RestoreDelay time.Duration
+}
+
+//FailoverEvent reports that a FailoverReceiver switched sources.
+type FailoverEvent struct {
+	OnBackup bool
+	Source   string
+}
+
+//The parts of RecvInstance that FailoverReceiver uses.
+type failoverRecv interface {
+	Connect(source *Source)
+	CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType
+	GetNumConnections(timeoutInMs uint32) (int, error)
+	Destroy()
+}
+
+//FailoverReceiver is a receiver that switches to a backup source when the primary one goes silent, and back once
+//the primary has disappeared and come back. A second, metadata only receiver watches the primary source while the
+//backup is used. Switching happens in Capture, it is not safe for concurrent use.
+type FailoverReceiver struct {
+	//When set, every switch is published as a FailoverEvent.
+	Bus *EventBus
+
+	recv, monitor   failoverRecv
+	primary, backup Source
+	state           failoverState
+}
+
+//NewFailoverReceiver creates the receivers and connects to primary. The source in settings is ignored.
+func NewFailoverReceiver(primary Source, backup Source, settings RecvCreateSettings, opts FailoverOptions) (*FailoverReceiver, error) {
+	settings.SourceToConnectTo = primary
+	recv := NewRecvInstanceV2(&settings)
+	if recv == nil {
+		return nil, createRecvErr
+	}
+
+	settings.Bandwidth = RecvBandwidthMetadataOnly
+	monitor := NewRecvInstanceV2(&settings)
+	if monitor == nil {
+		recv.Destroy()
+		return nil, createRecvErr
+	}
+	return newFailoverReceiver(recv, monitor, primary, backup, opts), nil
+}
+
+func newFailoverReceiver(recv, monitor failoverRecv, primary, backup Source, opts FailoverOptions) *FailoverReceiver {
+	if opts.FailoverDelay <= 0 {
+		opts.FailoverDelay = defaultFailoverDelay
+	}
+	if opts.RestoreDelay <= 0 {
+		opts.RestoreDelay = defaultRestoreDelay
+	}
+	return &FailoverReceiver{
+		recv:    recv,
+		monitor: monitor,
+		primary: primary,
+		backup:  backup,
+		state:   failoverState{opts: opts, lastFrame: sysClock.Now()},
+	}
+}
+
+//Capture captures from the source currently in use like RecvInstance.CaptureV2, switching sources first if needed.
+//Frames are freed with the Free methods of Receiver.
+func (r *FailoverReceiver) Capture(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	ft := r.recv.CaptureV2(vf, af, mf, timeoutInMs)
+
+	primaryUp := false
+	if r.state.onBackup {
+		n, err := r.monitor.GetNumConnections(0)
+		primaryUp = err == nil && n > 0
+	}
+
+	if r.state.update(sysClock.Now(), ft == FrameTypeVideo || ft == FrameTypeAudio, primaryUp) {
+		source := r.primary
+		if r.state.onBackup {
+			source = r.backup
+		}
+		r.recv.Connect(&source)
+		r.Bus.Publish(FailoverEvent{r.state.onBackup, source.Name()})
+	}
+	return ft
+}
+
+//Receiver returns the receiver frames are captured from, to free them or query it.
+func (r *FailoverReceiver) Receiver() *RecvInstance {
+	recv, _ := r.recv.(*RecvInstance)
+	return recv
+}
+
+//OnBackup reports whether the backup source is in use.
+func (r *FailoverReceiver) OnBackup() bool {
+	return r.state.onBackup
+}
+
+func (r *FailoverReceiver) Destroy() {
+	r.monitor.Destroy()
+	r.recv.Destroy()
+}
+
+//failoverState decides when a FailoverReceiver switches sources.
+type failoverState struct {
+	opts     FailoverOptions
+	onBackup bool
+
+	//When the source in use last delivered a frame, or when it was switched to.
+	lastFrame time.Time
+
+	//Whether the primary source was seen gone since the backup was switched to, and since when it is back.
+	primaryGone bool
+	primaryUp   bool
+	upSince     time.Time
+}
+
+//Feeds the result of one capture and whether the primary source is connected, which only matters while on the
+//backup. Returns whether to switch sources.
+func (s *failoverState) update(now time.Time, gotFrame, primaryUp bool) bool {
+	if gotFrame {
+		s.lastFrame = now
+	}
+
+	if !s.onBackup {
+		if now.Sub(s.lastFrame) < s.opts.FailoverDelay {
+			return false
+		}
+		*s = failoverState{opts: s.opts, onBackup: true, lastFrame: now}
+		return true
+	}
+
+	if !primaryUp {
+		s.primaryGone, s.primaryUp = true, false
+		return false
+	}
+	if !s.primaryGone {
+		return false
+	}
+	if !s.primaryUp {
+		s.primaryUp, s.upSince = true, now
+	}
+	if now.Sub(s.upSince) < s.opts.RestoreDelay {
+		return false
+	}
+	*s = failoverState{opts: s.opts, lastFrame: now}
+	return true
+}
@@ -0,0 +1,97 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailoverState(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := failoverState{opts: FailoverOptions{FailoverDelay: 2 * time.Second, RestoreDelay: 5 * time.Second}, lastFrame: start}
+
+	steps := []struct {
+		at        time.Duration
+		gotFrame  bool
+		primaryUp bool
+		switched  bool
+		onBackup  bool
+	}{
+		{1 * time.Second, true, false, false, false},
+		{2 * time.Second, false, false, false, false},
+		{3 * time.Second, false, false, true, true},
+		//The primary is still connected but silent, which does not count as back.
+		{10 * time.Second, false, true, false, true},
+		{11 * time.Second, true, false, false, true},
+		{12 * time.Second, true, true, false, true},
+		{14 * time.Second, true, false, false, true},
+		{15 * time.Second, true, true, false, true},
+		{20 * time.Second, true, true, true, false},
+		{21 * time.Second, true, true, false, false},
+	}
+	for _, step := range steps {
+		if switched := s.update(start.Add(step.at), step.gotFrame, step.primaryUp); switched != step.switched || s.onBackup != step.onBackup {
+			t.Errorf("At %v: Expected switched %v and on backup %v but got %v and %v.", step.at, step.switched, step.onBackup, switched, s.onBackup)
+		}
+	}
+}
+
+type fakeFailoverRecv struct {
+	frame     FrameType
+	connected string
+	up        int
+	destroyed bool
+}
+
+func (r *fakeFailoverRecv) Connect(source *Source) { r.connected = source.Name() }
+
+func (r *fakeFailoverRecv) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	return r.frame
+}
+
+func (r *fakeFailoverRecv) GetNumConnections(uint32) (int, error) { return r.up, nil }
+
+func (r *fakeFailoverRecv) Destroy() { r.destroyed = true }
+
+func TestFailoverReceiver(t *testing.T) {
+	c := useFakeClock(t)
+	recv, monitor := &fakeFailoverRecv{frame: FrameTypeVideo}, &fakeFailoverRecv{up: 1}
+	r := newFailoverReceiver(recv, monitor, NewSource("primary", ""), NewSource("backup", ""), FailoverOptions{})
+
+	var bus EventBus
+	events, unsubscribe := bus.Subscribe(4)
+	defer unsubscribe()
+	r.Bus = &bus
+
+	r.Capture(nil, nil, nil, 0)
+	recv.frame = FrameTypeNone
+	c.Advance(defaultFailoverDelay)
+	r.Capture(nil, nil, nil, 0)
+	if !r.OnBackup() || recv.connected != "backup" {
+		t.Fatalf("Expected a switch to the backup but connected to %q.", recv.connected)
+	}
+	if ev := (<-events).(FailoverEvent); !ev.OnBackup || ev.Source != "backup" {
+		t.Errorf("Unexpected event %+v.", ev)
+	}
+
+	monitor.up = 0
+	r.Capture(nil, nil, nil, 0)
+	monitor.up = 1
+	r.Capture(nil, nil, nil, 0)
+	c.Advance(defaultRestoreDelay)
+	r.Capture(nil, nil, nil, 0)
+	if r.OnBackup() || recv.connected != "primary" {
+		t.Errorf("Expected a switch back to the primary but connected to %q.", recv.connected)
+	}
+	if ev := (<-events).(FailoverEvent); ev.OnBackup || ev.Source != "primary" {
+		t.Errorf("Unexpected event %+v.", ev)
+	}
+
+	r.Destroy()
+	if !recv.destroyed || !monitor.destroyed {
+		t.Error("Expected both receivers to be destroyed.")
+	}
+}
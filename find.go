@@ -5,10 +5,19 @@
 package ndi
 
 import (
+	"errors"
+	"math"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
+var (
+	unknownFindInstanceErr = errors.New("find instance was not created by NewFindInstanceV2")
+	createFindInstanceErr  = errors.New("unable to create find instance")
+)
+
 type Source struct {
 	name, address *byte
 }
@@ -17,53 +26,184 @@ func (s *Source) Name() string {
 	if s.name == nil {
 		return ""
 	}
-	return goStringFromCString(uintptr(unsafe.Pointer(s.name)))
+	return goString(s.name)
 }
 
 func (s *Source) Address() string {
 	if s.address == nil {
 		return ""
 	}
-	return goStringFromCString(uintptr(unsafe.Pointer(s.address)))
+	return goString(s.address)
 }
 
 type FindInstance struct{}
 
+//FindSettings is the Go friendly form of FindCreateSettings.
+type FindSettings struct {
+	//Whether sources running on this machine are listed.
+	ShowLocalSources bool
+
+	//Comma separated list of the groups to search, empty for the default groups.
+	Groups string
+
+	//Comma separated list of additional IP addresses to query for sources, for networks without mDNS.
+	ExtraIPs string
+}
+
+//Returns the SDK defaults: local sources are shown and the default groups are searched.
+func NewFindSettings() *FindSettings {
+	return &FindSettings{ShowLocalSources: true}
+}
+
+//Creates a finder from settings, see NewFindInstanceV2.
+func NewFindInstance(settings *FindSettings) *FindInstance {
+	return NewFindInstanceV2(&FindCreateSettings{
+		showLocalSources: settings.ShowLocalSources,
+		groups:           cString(settings.Groups),
+		extraIPs:         cString(settings.ExtraIPs),
+	})
+}
+
+//The SDK does not keep the settings a finder was created with, remember them so that it can be recreated.
+var (
+	findSettingsMu sync.Mutex
+	findSettings   = make(map[*FindInstance]*FindCreateSettings)
+)
+
 func NewFindInstanceV2(settings *FindCreateSettings) *FindInstance {
 	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibFindCreateV2, 1, uintptr(unsafe.Pointer(settings)), 0, 0)
 	if eno != 0 {
 		panic(eno)
 	}
-	return (*FindInstance)(unsafe.Pointer(ret))
+
+	inst := (*FindInstance)(unsafe.Pointer(ret))
+	if inst != nil {
+		findSettingsMu.Lock()
+		findSettings[inst] = settings
+		findSettingsMu.Unlock()
+	}
+	return inst
 }
 
 func (inst *FindInstance) Destroy() {
+	findSettingsMu.Lock()
+	delete(findSettings, inst)
+	findSettingsMu.Unlock()
+	inst.SetMaxSources(0)
+
 	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibFindDestroy, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
 		panic(eno)
 	}
 }
 
+//The SDK has no way of changing the extra IPs of a running finder. This creates a new finder with the same
+//settings but the given comma separated list of extra IPs and destroys inst, which must not be used afterwards.
+//The limit set with SetMaxSources is carried over.
+//On failure inst is left untouched.
+func (inst *FindInstance) WithExtraIPs(ips string) (*FindInstance, error) {
+	findSettingsMu.Lock()
+	old, ok := findSettings[inst]
+	findSettingsMu.Unlock()
+	if !ok {
+		return nil, unknownFindInstanceErr
+	}
+
+	settings := &FindCreateSettings{
+		showLocalSources: old.showLocalSources,
+		groups:           old.groups,
+		extraIPs:         cString(ips),
+	}
+
+	newInst := NewFindInstanceV2(settings)
+	if newInst == nil {
+		return nil, createFindInstanceErr
+	}
+
+	sourceLimitsMu.Lock()
+	if l, ok := sourceLimits[inst]; ok {
+		sourceLimits[newInst] = l
+	}
+	sourceLimitsMu.Unlock()
+
+	inst.Destroy()
+	return newInst, nil
+}
+
 //This will allow you to wait until the number of online sources have changed.
+//The SDK returns a C bool, so the result is 1 if the sources changed and 0 if not.
 func (inst *FindInstance) WaitForSources(timeoutInMs uint32) (int, error) {
 	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibFindWaitForSources, 2, uintptr(unsafe.Pointer(inst)), uintptr(timeoutInMs), 0)
 	if eno != 0 {
 		return 0, Error{eno}
 	}
-	return int(ret), nil
+	if byte(ret) == 0 {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+//Like WaitForSources, but takes a duration and reports whether the list of sources changed before it ran out.
+//Negative timeouts are treated as zero and the timeout is capped at what the SDK accepts. It may be called while another goroutine calls GetCurrentSources.
+func (inst *FindInstance) WaitForSourcesChanged(timeout time.Duration) bool {
+	ms := timeout / time.Millisecond
+	if ms < 0 {
+		ms = 0
+	} else if ms > math.MaxUint32 {
+		ms = math.MaxUint32
+	}
+	changed, err := inst.WaitForSources(uint32(ms))
+	return err == nil && changed != 0
 }
 
 //This function will recover the current set of sources (i.e. the ones that exist right this second).
+//The names and addresses are copied out of SDK memory, so the sources stay valid after the next call.
+//The list is limited as set with SetMaxSources.
 func (inst *FindInstance) GetCurrentSources() []*Source {
 	var numSources uint32
 	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibFindGetCurrentSources, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(&numSources)), 0)
 	if eno != 0 {
 		panic(eno)
 	}
+	return inst.limitSources(copySources(ret, numSources))
+}
 
-	sources := make([]*Source, numSources)
-	for i, s := range sources {
-		sources[i] = (*Source)(unsafe.Pointer(ret))
-		ret += unsafe.Sizeof(*s)
+//Like GetCurrentSources, but waits up to timeoutInMs for the first sources to show up. Deprecated in the SDK in
+//favor of WaitForSources and GetCurrentSources.
+func (inst *FindInstance) GetSources(timeoutInMs uint32) []*Source {
+	var numSources uint32
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibFindGetSources, 3, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(&numSources)), uintptr(timeoutInMs))
+	if eno != 0 {
+		panic(eno)
+	}
+	return inst.limitSources(copySources(ret, numSources))
+}
+
+//Copies a source list owned by the SDK into Go memory, it is invalidated by the next call of the finder.
+func copySources(p uintptr, n uint32) []*Source {
+	if p == 0 {
+		return nil
+	}
+
+	sdkSources := unsafe.Slice((*Source)(unsafe.Pointer(p)), n)
+	sources := make([]*Source, n)
+	for i := range sdkSources {
+		s := NewSource(sdkSources[i].Name(), sdkSources[i].Address())
+		sources[i] = &s
 	}
 	return sources
 }
+
+//Copies a single source owned by the SDK into Go memory, the zero Source if p is NULL.
+func copySource(p uintptr) Source {
+	if p == 0 {
+		return Source{}
+	}
+	s := (*Source)(unsafe.Pointer(p))
+	return NewSource(s.Name(), s.Address())
+}
+
+//Creates a source description that can be connected to by name. The address may be left empty in which case
+//the SDK looks the source up on the network.
+func NewSource(name, address string) Source {
+	return Source{name: cString(name), address: cString(address)}
+}
@@ -0,0 +1,93 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//The limits set with SetMaxSources, the SDK handle cannot carry them.
+var (
+	sourceLimitsMu sync.Mutex
+	sourceLimits   = make(map[*FindInstance]*sourceLimit)
+)
+
+//sourceLimit keeps the n most recently seen sources of a finder. A source counts as seen when it shows up in the
+//list, either for the first time, after it was gone or with a new address.
+type sourceLimit struct {
+	n    int
+	seen map[string]seenSource
+}
+
+type seenSource struct {
+	address string
+	at      time.Time
+}
+
+//SetMaxSources limits GetCurrentSources and GetSources to the n most recently seen sources, for networks with so
+//many sources that the full list would overwhelm the application. Sources that stay on the network keep their
+//place, newly announced ones push out the oldest. The sources keep the order of the SDK. Zero or less removes the
+//limit.
+func (inst *FindInstance) SetMaxSources(n int) {
+	sourceLimitsMu.Lock()
+	defer sourceLimitsMu.Unlock()
+
+	if n <= 0 {
+		delete(sourceLimits, inst)
+		return
+	}
+	if l, ok := sourceLimits[inst]; ok {
+		l.n = n
+		return
+	}
+	sourceLimits[inst] = &sourceLimit{n: n, seen: make(map[string]seenSource)}
+}
+
+//Applies the limit set with SetMaxSources to sources.
+func (inst *FindInstance) limitSources(sources []*Source) []*Source {
+	sourceLimitsMu.Lock()
+	defer sourceLimitsMu.Unlock()
+
+	l, ok := sourceLimits[inst]
+	if !ok {
+		return sources
+	}
+	return l.filter(sysClock.Now(), sources)
+}
+
+func (l *sourceLimit) filter(now time.Time, sources []*Source) []*Source {
+	seen := make(map[string]seenSource, len(sources))
+	for _, s := range sources {
+		name, address := s.Name(), s.Address()
+		if prev, ok := l.seen[name]; ok && prev.address == address {
+			seen[name] = prev
+		} else {
+			seen[name] = seenSource{address, now}
+		}
+	}
+	l.seen = seen
+
+	if len(sources) <= l.n {
+		return sources
+	}
+
+	newest := make([]int, len(sources))
+	for i := range newest {
+		newest[i] = i
+	}
+	sort.SliceStable(newest, func(i, j int) bool {
+		return seen[sources[newest[i]].Name()].at.After(seen[sources[newest[j]].Name()].at)
+	})
+	newest = newest[:l.n]
+	sort.Ints(newest)
+
+	limited := make([]*Source, len(newest))
+	for i, idx := range newest {
+		limited[i] = sources[idx]
+	}
+	return limited
+}
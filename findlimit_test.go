@@ -0,0 +1,71 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSourceLimit(t *testing.T) {
+	c := useFakeClock(t)
+	list := func(names ...string) []*Source {
+		sources := make([]*Source, len(names))
+		for i, name := range names {
+			s := NewSource(name, "10.0.0.1:5961")
+			sources[i] = &s
+		}
+		return sources
+	}
+	names := func(sources []*Source) []string {
+		var n []string
+		for _, s := range sources {
+			n = append(n, s.Name())
+		}
+		return n
+	}
+
+	inst := &FindInstance{}
+	if got := inst.limitSources(list("A", "B", "C")); len(got) != 3 {
+		t.Errorf("Expected no limit by default but got %v.", names(got))
+	}
+
+	inst.SetMaxSources(2)
+	defer inst.SetMaxSources(0)
+
+	steps := []struct {
+		sources []*Source
+		want    []string
+	}{
+		//Seen at the same time, the SDK order decides.
+		{list("A", "B", "C"), []string{"A", "B"}},
+		//D is newer than all of them.
+		{list("A", "B", "C", "D"), []string{"A", "D"}},
+		//C came back after it was gone.
+		{list("A", "B", "D"), []string{"A", "D"}},
+		{list("C", "A", "B", "D"), []string{"C", "D"}},
+	}
+	for i, step := range steps {
+		c.Advance(time.Second)
+		if got := names(inst.limitSources(step.sources)); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("Step %d: Expected %v but got %v.", i, step.want, got)
+		}
+	}
+
+	//A new address counts as newly seen.
+	c.Advance(time.Second)
+	moved := list("C", "A", "B", "D")
+	b := NewSource("B", "10.0.0.2:5961")
+	moved[2] = &b
+	if got := names(inst.limitSources(moved)); !reflect.DeepEqual(got, []string{"C", "B"}) {
+		t.Errorf("Expected [C B] but got %v.", got)
+	}
+
+	inst.SetMaxSources(0)
+	if got := inst.limitSources(list("A", "B", "C")); len(got) != 3 {
+		t.Errorf("Expected the limit to be removed but got %v.", names(got))
+	}
+}
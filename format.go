@@ -0,0 +1,104 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+//VideoFormat is the part of a video frame that buffers and stream headers are sized from.
+type VideoFormat struct {
+	Xres, Yres             int32
+	FourCC                 [4]byte
+	FrameRateN, FrameRateD int32
+}
+
+func (vf *VideoFrameV2) Format() VideoFormat {
+	return VideoFormat{vf.Xres, vf.Yres, vf.FourCC, vf.FrameRateN, vf.FrameRateD}
+}
+
+//FormatChange describes a change of the video format between two consecutive frames.
+type FormatChange struct {
+	Old, New VideoFormat
+}
+
+//FormatTracker detects when received video changes format mid-stream, for instance when a camera switches
+//to 4K. Feed it every received video frame before handling the frame.
+type FormatTracker struct {
+	//If set, changes are also published here.
+	Bus *EventBus
+
+	format VideoFormat
+	seen   bool
+}
+
+//Update records the format of vf and reports whether it differs from the previous frame.
+//The first frame is not reported as a change.
+func (t *FormatTracker) Update(vf *VideoFrameV2) (FormatChange, bool) {
+	f := vf.Format()
+	if !t.seen {
+		t.format, t.seen = f, true
+		return FormatChange{}, false
+	}
+
+	if f == t.format {
+		return FormatChange{}, false
+	}
+
+	change := FormatChange{t.format, f}
+	t.format = f
+	t.Bus.Publish(change)
+	return change, true
+}
+
+//Format returns the format of the last frame passed to Update.
+func (t *FormatTracker) Format() (VideoFormat, bool) {
+	return t.format, t.seen
+}
+
+//AudioFormat is the part of an audio frame that buffers and stream headers are sized from.
+type AudioFormat struct {
+	SampleRate, NumChannels int32
+}
+
+func (af *AudioFrameV2) Format() AudioFormat {
+	return AudioFormat{af.SampleRate, af.NumChannels}
+}
+
+//AudioFormatChange describes a change of the audio format between two consecutive frames.
+type AudioFormatChange struct {
+	Old, New AudioFormat
+}
+
+//AudioFormatTracker is the audio counterpart of FormatTracker, for sources that switch for instance from stereo
+//to 8 channels mid-stream. The limiter, the sample rate detector and the lip sync corrector adapt to such changes
+//on their own, anything that writes the audio out with a fixed header has to be restarted.
+type AudioFormatTracker struct {
+	//If set, changes are also published here.
+	Bus *EventBus
+
+	format AudioFormat
+	seen   bool
+}
+
+//Update records the format of af and reports whether it differs from the previous frame.
+//The first frame is not reported as a change.
+func (t *AudioFormatTracker) Update(af *AudioFrameV2) (AudioFormatChange, bool) {
+	f := af.Format()
+	if !t.seen {
+		t.format, t.seen = f, true
+		return AudioFormatChange{}, false
+	}
+
+	if f == t.format {
+		return AudioFormatChange{}, false
+	}
+
+	change := AudioFormatChange{t.format, f}
+	t.format = f
+	t.Bus.Publish(change)
+	return change, true
+}
+
+//Format returns the format of the last frame passed to Update.
+func (t *AudioFormatTracker) Format() (AudioFormat, bool) {
+	return t.format, t.seen
+}
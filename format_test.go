@@ -0,0 +1,75 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTracker(t *testing.T) {
+	var tracker FormatTracker
+
+	vf := NewVideoFrameV2()
+	vf.Xres, vf.Yres = 1920, 1080
+	if _, changed := tracker.Update(vf); changed {
+		t.Error("The first frame must not be reported as a change.")
+	}
+	if _, changed := tracker.Update(vf); changed {
+		t.Error("An unchanged format must not be reported.")
+	}
+
+	old := vf.Format()
+	vf.Xres, vf.Yres = 3840, 2160
+	change, changed := tracker.Update(vf)
+	if !changed || change.Old != old || change.New != vf.Format() {
+		t.Errorf("Expected a change from %+v to %+v but got %+v (%v).", old, vf.Format(), change, changed)
+	}
+}
+
+func TestAudioFormatChange(t *testing.T) {
+	frame := func(channels int) *AudioFrameV2 {
+		samples := make([][]float32, channels)
+		for ch := range samples {
+			samples[ch] = []float32{1, 1, 1, 1}
+		}
+		return newPlanarAudioFrame(samples, 48000)
+	}
+	stereo, surround := frame(2), frame(8)
+
+	var tracker AudioFormatTracker
+	tracker.Update(stereo)
+	change, changed := tracker.Update(surround)
+	if !changed || change.Old != stereo.Format() || change.New != surround.Format() {
+		t.Errorf("Expected a change from %+v to %+v but got %+v (%v).", stereo.Format(), surround.Format(), change, changed)
+	}
+
+	//The limiter must cover every channel of the new layout.
+	limiter := NewLimiter(-6, 0, 100)
+	for _, af := range []*AudioFrameV2{stereo, surround} {
+		if err := limiter.Process(af); err != nil {
+			t.Fatal(err)
+		}
+		for ch := 0; ch < int(af.NumChannels); ch++ {
+			if s := af.ReadChannel(ch)[0]; s > 0.51 {
+				t.Errorf("%d channels: Expected channel %d to be limited but got %v.", af.NumChannels, ch, s)
+			}
+		}
+	}
+
+	//The lip sync delay line must be rebuilt for the new layout.
+	c := &LipSyncCorrector{offset: -time.Second / 12000}
+	for _, channels := range []int{2, 8} {
+		af := frame(channels)
+		captured := make([][]float32, channels)
+		for ch := range captured {
+			captured[ch] = af.ReadChannel(ch)
+		}
+		out := c.delayAudio(captured, 48000)
+		if len(out) != channels || len(out[0]) != 4 {
+			t.Errorf("Expected %d channels of 4 samples but got %d channels.", channels, len(out))
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+//The frame synchronizer turns the push based receiver into a pull based one, time base corrected to the local
+//clock. Video is returned as the most recent frame (repeating or dropping as needed) and audio is resampled
+//to the amount requested.
+type FramesyncInstance struct{}
+
+//Creates a frame synchronizer on top of a receiver. The receiver must outlive the frame synchronizer and
+//should no longer be captured from directly.
+func NewFramesyncInstance(recv *RecvInstance) *FramesyncInstance {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncInstanceT, 1, uintptr(unsafe.Pointer(recv)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return (*FramesyncInstance)(unsafe.Pointer(ret))
+}
+
+func (inst *FramesyncInstance) Destroy() {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncDestroy, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Pulls the current video frame. If no video has been received yet the frame has a nil Data pointer.
+//The frame must be freed with FreeVideo.
+func (inst *FramesyncInstance) CaptureVideo(vf *VideoFrameV2, fieldType FrameFormat) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncCaptureVideo, 3, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(vf)), uintptr(fieldType)); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Like CaptureVideo, but allocates the frame. Returns nil if no video has been received yet.
+//The frame must be freed with FreeVideo.
+func (inst *FramesyncInstance) CaptureVideoFrame(fieldType FrameFormat) *VideoFrameV2 {
+	vf := &VideoFrameV2{}
+	inst.CaptureVideo(vf, fieldType)
+	if vf.Data == nil {
+		inst.FreeVideo(vf)
+		return nil
+	}
+	return vf
+}
+
+//Frees a frame from CaptureVideo or CaptureVideoFrame. Frames of the frame synchronizer must never be freed through
+//the receiver it was created from.
+func (inst *FramesyncInstance) FreeVideo(vf *VideoFrameV2) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncFreeVideo, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(vf)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Pulls exactly numSamples of audio, resampled to the given format. Silence is returned if no audio has been
+//received. Passing zero for the sample rate or channel count uses the format of the source.
+//The frame must be freed with FreeAudio.
+func (inst *FramesyncInstance) CaptureAudio(af *AudioFrameV2, sampleRate, numChannels, numSamples int) {
+	if _, _, eno := syscall.Syscall6(
+		funcPtrs.NDIlibFramesyncCaptureAudio,
+		5,
+		uintptr(unsafe.Pointer(inst)),
+		uintptr(unsafe.Pointer(af)),
+		uintptr(sampleRate),
+		uintptr(numChannels),
+		uintptr(numSamples),
+		0,
+	); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Like CaptureAudio, but allocates the frame. The frame must be freed with FreeAudio.
+func (inst *FramesyncInstance) CaptureAudioFrame(sampleRate, numChannels, numSamples int) *AudioFrameV2 {
+	af := &AudioFrameV2{}
+	inst.CaptureAudio(af, sampleRate, numChannels, numSamples)
+	return af
+}
+
+//Frees a frame from CaptureAudio or CaptureAudioFrame, see FreeVideo.
+func (inst *FramesyncInstance) FreeAudio(af *AudioFrameV2) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncFreeAudio, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(af)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Like CaptureAudio, but fills an NDI 4 audio frame. The frame must be freed with FreeAudioV2.
+func (inst *FramesyncInstance) CaptureAudioV2(sampleRate, numChannels, numSamples int) *AudioFrameV3 {
+	af := &AudioFrameV3{}
+	if _, _, eno := syscall.Syscall6(
+		funcPtrs.NDIlibFramesyncCaptureAudioV2,
+		5,
+		uintptr(unsafe.Pointer(inst)),
+		uintptr(unsafe.Pointer(af)),
+		uintptr(sampleRate),
+		uintptr(numChannels),
+		uintptr(numSamples),
+		0,
+	); eno != 0 {
+		panic(eno)
+	}
+	return af
+}
+
+func (inst *FramesyncInstance) FreeAudioV2(af *AudioFrameV3) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncFreeAudioV2, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(af)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Returns the number of audio samples per channel that are buffered and not yet captured. A growing depth means
+//audio is captured slower than it arrives. Capturing no more than the depth avoids the silence the frame
+//synchronizer inserts on an underrun, see DrainAudioV2.
+func (inst *FramesyncInstance) AudioQueueDepth() int {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibFramesyncAudioQueueDepth, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return int(int32(ret))
+}
+
+//Captures the buffered audio in chunks of chunkSize samples per channel with CaptureAudioV2 and passes each one to
+//fn, as long as at least a full chunk is buffered. The frames are freed after fn returns, so fn must copy what it
+//keeps. Returns the number of chunks, the remainder stays buffered for the next call.
+func (inst *FramesyncInstance) DrainAudioV2(sampleRate, numChannels, chunkSize int, fn func(af *AudioFrameV3)) int {
+	if chunkSize <= 0 {
+		return 0
+	}
+
+	var n int
+	for inst.AudioQueueDepth() >= chunkSize {
+		af := inst.CaptureAudioV2(sampleRate, numChannels, chunkSize)
+		fn(af)
+		inst.FreeAudioV2(af)
+		n++
+	}
+	return n
+}
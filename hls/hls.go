@@ -0,0 +1,273 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+//Package hls republishes an NDI source as HTTP Live Streaming.
+//
+//Encoding is pluggable: an Encoder turns the captured frames into MPEG-TS segments (H.264/AAC),
+//typically by wrapping an external encoder. This package captures, cuts segments and maintains the playlist.
+package hls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/FlowingSPDG/ndi-go"
+)
+
+var (
+	missingEncoderErr = errors.New("hls: an encoder is required")
+	connectionLostErr = errors.New("hls: connection to the source was lost")
+)
+
+const (
+	playlistName   = "playlist.m3u8"
+	captureTimeout = 100
+)
+
+type PlaylistType int
+
+const (
+	//A sliding window playlist holding at most MaxSegments segments.
+	PlaylistLive PlaylistType = iota
+
+	//A playlist that keeps every segment, MaxSegments is ignored.
+	PlaylistEvent
+)
+
+//Encoder encodes frames into MPEG-TS. Frames are only valid for the duration of the call.
+type Encoder interface {
+	//StartSegment starts a new segment written to w. The segment must start with a key frame.
+	StartSegment(w io.Writer) error
+	EncodeVideo(vf *ndi.VideoFrameV2) error
+	EncodeAudio(af *ndi.AudioFrameV2) error
+	//EndSegment flushes everything of the current segment to its writer.
+	EndSegment() error
+}
+
+type HLSOptions struct {
+	//The target duration of a segment, segments are cut on the first video frame after it elapsed. Defaults to 6s.
+	//Rounded up to whole seconds it is also the target duration of the playlist, which must not change while
+	//streaming. Segments run over by up to one frame, which players allow for as long as frames are less than half
+	//a second apart.
+	SegmentDuration time.Duration
+
+	//The number of segments kept in a live playlist. Defaults to 5.
+	MaxSegments int
+
+	PlaylistType PlaylistType
+	Encoder      Encoder
+}
+
+//The parts of ndi.RecvInstance that HLSEgress uses.
+type receiver interface {
+	CaptureV2(vf *ndi.VideoFrameV2, af *ndi.AudioFrameV2, mf *ndi.MetadataFrame, timeoutInMs uint32) ndi.FrameType
+	FreeVideoV2(vf *ndi.VideoFrameV2)
+	FreeAudioV2(af *ndi.AudioFrameV2)
+}
+
+//HLSEgress captures from a receiver and writes segments and playlist.m3u8 to a directory.
+type HLSEgress struct {
+	recv receiver
+	dir  string
+	opts HLSOptions
+
+	playlist playlist
+	segment  *os.File
+	segStart int64
+	segIndex int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+	err    error
+}
+
+//NewHLSEgress starts capturing from recv. The egress owns the capture loop of recv until Close is called.
+func NewHLSEgress(recv *ndi.RecvInstance, dir string, opts HLSOptions) (*HLSEgress, error) {
+	return newHLSEgress(recv, dir, opts)
+}
+
+func newHLSEgress(recv receiver, dir string, opts HLSOptions) (*HLSEgress, error) {
+	if opts.Encoder == nil {
+		return nil, missingEncoderErr
+	}
+	if opts.SegmentDuration <= 0 {
+		opts.SegmentDuration = 6 * time.Second
+	}
+	if opts.MaxSegments <= 0 {
+		opts.MaxSegments = 5
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &HLSEgress{
+		recv:     recv,
+		dir:      dir,
+		opts:     opts,
+		playlist: playlist{typ: opts.PlaylistType, maxSegments: opts.MaxSegments, target: opts.SegmentDuration},
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(e.done)
+		if err := e.run(ctx); err != nil {
+			e.mu.Lock()
+			e.err = err
+			e.mu.Unlock()
+		}
+	}()
+	return e, nil
+}
+
+//Close stops capturing, finishes the current segment, ends the playlist and returns the first error the egress
+//ran into.
+func (e *HLSEgress) Close() error {
+	e.cancel()
+	<-e.done
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+//Err returns the error that stopped the egress, if any. Once it is set no more segments are written and the
+//playlist has been ended.
+func (e *HLSEgress) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+//Captures until ctx is done or the egress fails. Either way the current segment is finished and the playlist is
+//ended, so that players stop polling for more segments.
+func (e *HLSEgress) run(ctx context.Context) error {
+	err := e.capture(ctx)
+	if serr := e.closeSegment(0); err == nil {
+		err = serr
+	}
+	e.playlist.ended = true
+	if perr := e.writePlaylist(); err == nil {
+		err = perr
+	}
+	return err
+}
+
+func (e *HLSEgress) capture(ctx context.Context) error {
+	var (
+		vf ndi.VideoFrameV2
+		af ndi.AudioFrameV2
+	)
+
+	for ctx.Err() == nil {
+		switch e.recv.CaptureV2(&vf, &af, nil, captureTimeout) {
+		case ndi.FrameTypeVideo:
+			err := e.video(&vf)
+			e.recv.FreeVideoV2(&vf)
+			if err != nil {
+				return err
+			}
+
+		case ndi.FrameTypeAudio:
+			var err error
+			if e.segment != nil {
+				err = e.opts.Encoder.EncodeAudio(&af)
+			}
+			e.recv.FreeAudioV2(&af)
+			if err != nil {
+				return err
+			}
+
+		case ndi.FrameTypeError:
+			return connectionLostErr
+		}
+	}
+	return nil
+}
+
+//Handles a video frame, cutting a new segment when the current one is long enough.
+func (e *HLSEgress) video(vf *ndi.VideoFrameV2) error {
+	if e.segment == nil || time.Duration(vf.Timecode-e.segStart)*100 >= e.opts.SegmentDuration {
+		if err := e.closeSegment(vf.Timecode); err != nil {
+			return err
+		}
+		if err := e.openSegment(vf.Timecode); err != nil {
+			return err
+		}
+	}
+	return e.opts.Encoder.EncodeVideo(vf)
+}
+
+func (e *HLSEgress) openSegment(timecode int64) error {
+	f, err := os.Create(filepath.Join(e.dir, segmentName(e.segIndex)))
+	if err != nil {
+		return err
+	}
+	if err := e.opts.Encoder.StartSegment(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	e.segment = f
+	e.segStart = timecode
+	return nil
+}
+
+//Finishes the current segment which ends at timecode, or at its last frame for a zero timecode, and publishes it.
+func (e *HLSEgress) closeSegment(timecode int64) error {
+	if e.segment == nil {
+		return nil
+	}
+
+	f := e.segment
+	e.segment = nil
+
+	err := e.opts.Encoder.EndSegment()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	duration := e.opts.SegmentDuration
+	if timecode != 0 {
+		duration = time.Duration(timecode-e.segStart) * 100
+	}
+
+	removed := e.playlist.add(segmentName(e.segIndex), duration)
+	e.segIndex++
+	if err := e.writePlaylist(); err != nil {
+		return err
+	}
+
+	//Only once the playlist no longer refers to them.
+	for _, name := range removed {
+		if err := os.Remove(filepath.Join(e.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+//Writes the playlist atomically so that HTTP servers never serve a partial file.
+func (e *HLSEgress) writePlaylist() error {
+	tmp := filepath.Join(e.dir, playlistName+".tmp")
+	if err := os.WriteFile(tmp, []byte(e.playlist.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(e.dir, playlistName))
+}
+
+func segmentName(index int) string {
+	return fmt.Sprintf("segment%06d.ts", index)
+}
@@ -0,0 +1,166 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package hls
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/ndi-go"
+)
+
+//Plays a list of frames and then reports a lost connection, or nothing at all if lost is false.
+type fakeReceiver struct {
+	frames  []ndi.FrameType
+	lost    bool
+	drained chan struct{}
+
+	next     int
+	timecode int64
+}
+
+func newFakeReceiver(frames []ndi.FrameType, lost bool) *fakeReceiver {
+	return &fakeReceiver{frames: frames, lost: lost, drained: make(chan struct{})}
+}
+
+//The video frames are one second apart, starting at zero.
+func (r *fakeReceiver) CaptureV2(vf *ndi.VideoFrameV2, af *ndi.AudioFrameV2, mf *ndi.MetadataFrame, timeoutInMs uint32) ndi.FrameType {
+	if r.next == len(r.frames) {
+		r.next++
+		close(r.drained)
+	}
+	if r.next > len(r.frames) {
+		if r.lost {
+			return ndi.FrameTypeError
+		}
+		return ndi.FrameTypeNone
+	}
+
+	ft := r.frames[r.next]
+	r.next++
+	if ft == ndi.FrameTypeVideo {
+		*vf = ndi.VideoFrameV2{Timecode: r.timecode}
+		r.timecode += int64(time.Second / 100)
+	}
+	return ft
+}
+
+func (r *fakeReceiver) FreeVideoV2(vf *ndi.VideoFrameV2) {}
+func (r *fakeReceiver) FreeAudioV2(af *ndi.AudioFrameV2) {}
+
+//Writes the second of every video frame and an "a" for every audio frame into the segments.
+type fakeEncoder struct {
+	w io.Writer
+}
+
+func (e *fakeEncoder) StartSegment(w io.Writer) error {
+	e.w = w
+	return nil
+}
+
+func (e *fakeEncoder) EncodeVideo(vf *ndi.VideoFrameV2) error {
+	_, err := fmt.Fprintf(e.w, "v%d ", time.Duration(vf.Timecode)*100/time.Second)
+	return err
+}
+
+func (e *fakeEncoder) EncodeAudio(af *ndi.AudioFrameV2) error {
+	_, err := io.WriteString(e.w, "a ")
+	return err
+}
+
+func (e *fakeEncoder) EndSegment() error {
+	e.w = nil
+	return nil
+}
+
+func checkDir(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	var expected []string
+	for name := range files {
+		expected = append(expected, name)
+	}
+	if len(names) != len(expected) {
+		t.Errorf("Expected the files %v but got %v.", expected, names)
+	}
+
+	for name, content := range files {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(b) != content {
+			t.Errorf("Unexpected content of %s:\n%s", name, b)
+		}
+	}
+}
+
+func TestHLSEgressRotation(t *testing.T) {
+	v, a := ndi.FrameTypeVideo, ndi.FrameTypeAudio
+	recv := newFakeReceiver([]ndi.FrameType{a, v, a, v, v, v, v, v, v, v}, true)
+
+	dir := t.TempDir()
+	e, err := newHLSEgress(recv, dir, HLSOptions{SegmentDuration: 2 * time.Second, MaxSegments: 2, Encoder: &fakeEncoder{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-e.done
+
+	if err := e.Err(); err != connectionLostErr {
+		t.Errorf("Expected %v but got %v.", connectionLostErr, err)
+	}
+
+	//Audio ahead of the first segment is dropped. The two oldest of the four segments fell out of the window
+	//and were deleted, the last one was finished when the connection was lost.
+	checkDir(t, dir, map[string]string{
+		"segment000002.ts": "v4 v5 ",
+		"segment000003.ts": "v6 v7 ",
+		"playlist.m3u8": "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:2\n" +
+			"#EXTINF:2.000,\nsegment000002.ts\n#EXTINF:2.000,\nsegment000003.ts\n#EXT-X-ENDLIST\n",
+	})
+
+	if err := e.Close(); err != connectionLostErr {
+		t.Errorf("Expected Close to return %v but got %v.", connectionLostErr, err)
+	}
+}
+
+func TestHLSEgressClose(t *testing.T) {
+	v, a := ndi.FrameTypeVideo, ndi.FrameTypeAudio
+	recv := newFakeReceiver([]ndi.FrameType{v, a, v, v, a}, false)
+
+	dir := t.TempDir()
+	e, err := newHLSEgress(recv, dir, HLSOptions{SegmentDuration: 2 * time.Second, PlaylistType: PlaylistEvent, Encoder: &fakeEncoder{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-recv.drained
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	checkDir(t, dir, map[string]string{
+		"segment000000.ts": "v0 a v1 ",
+		"segment000001.ts": "v2 a ",
+		"playlist.m3u8": "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:EVENT\n" +
+			"#EXTINF:2.000,\nsegment000000.ts\n#EXTINF:2.000,\nsegment000001.ts\n#EXT-X-ENDLIST\n",
+	})
+
+	if _, err := newHLSEgress(recv, dir, HLSOptions{}); err != missingEncoderErr {
+		t.Errorf("Expected %v but got %v.", missingEncoderErr, err)
+	}
+}
@@ -0,0 +1,65 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package hls
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type playlistSegment struct {
+	name     string
+	duration time.Duration
+}
+
+type playlist struct {
+	typ         PlaylistType
+	maxSegments int
+	target      time.Duration
+
+	segments []playlistSegment
+	sequence int
+
+	//Set once no more segments are added.
+	ended bool
+}
+
+//Appends a segment and returns the names of the segments that fell out of a live playlist.
+func (p *playlist) add(name string, duration time.Duration) []string {
+	p.segments = append(p.segments, playlistSegment{name, duration})
+	if p.typ != PlaylistLive || len(p.segments) <= p.maxSegments {
+		return nil
+	}
+
+	n := len(p.segments) - p.maxSegments
+	removed := make([]string, n)
+	for i, s := range p.segments[:n] {
+		removed[i] = s.name
+	}
+	p.segments = append(p.segments[:0], p.segments[n:]...)
+	p.sequence += n
+	return removed
+}
+
+//The target duration is the configured one rather than that of the longest segment, it must not change while the
+//playlist is served.
+func (p *playlist) String() string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(p.target.Seconds())))
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", p.sequence)
+	if p.typ == PlaylistEvent {
+		b.WriteString("#EXT-X-PLAYLIST-TYPE:EVENT\n")
+	}
+	for _, s := range p.segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", s.duration.Seconds(), s.name)
+	}
+	if p.ended {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return b.String()
+}
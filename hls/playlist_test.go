@@ -0,0 +1,49 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package hls
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLivePlaylist(t *testing.T) {
+	p := playlist{typ: PlaylistLive, maxSegments: 2, target: 6 * time.Second}
+
+	p.add("segment000000.ts", 6*time.Second)
+	p.add("segment000001.ts", 6*time.Second)
+	removed := p.add("segment000002.ts", 6033*time.Millisecond)
+	if !reflect.DeepEqual(removed, []string{"segment000000.ts"}) {
+		t.Errorf("Expected the oldest segment to be removed but got %v.", removed)
+	}
+
+	//The segment that ran over by a frame leaves the target duration alone.
+	expected := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:6\n#EXT-X-MEDIA-SEQUENCE:1\n" +
+		"#EXTINF:6.000,\nsegment000001.ts\n#EXTINF:6.033,\nsegment000002.ts\n"
+	if s := p.String(); s != expected {
+		t.Errorf("Unexpected playlist:\n%s", s)
+	}
+}
+
+func TestEventPlaylist(t *testing.T) {
+	p := playlist{typ: PlaylistEvent, maxSegments: 1, target: 2 * time.Second}
+	for i := 0; i < 3; i++ {
+		if removed := p.add(segmentName(i), 2*time.Second); removed != nil {
+			t.Errorf("Event playlists must keep every segment but %v was removed.", removed)
+		}
+	}
+
+	expected := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:EVENT\n" +
+		"#EXTINF:2.000,\nsegment000000.ts\n#EXTINF:2.000,\nsegment000001.ts\n#EXTINF:2.000,\nsegment000002.ts\n"
+	if s := p.String(); s != expected {
+		t.Errorf("Unexpected playlist:\n%s", s)
+	}
+
+	p.ended = true
+	if s := p.String(); s != expected+"#EXT-X-ENDLIST\n" {
+		t.Errorf("Unexpected ended playlist:\n%s", s)
+	}
+}
@@ -0,0 +1,182 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+//Package hx stakes out the encode and decode side of NDI|HX2 streams. Only PassThrough, which frames the data
+//without compressing it, is implemented. Real H.264 and H.265 codecs plug in through RegisterCodec, so the
+//package itself has no cgo or codec dependency.
+package hx
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+var (
+	unsupportedCodecErr = errors.New("no implementation registered for the HX codec")
+	shortPacketErr      = errors.New("packet is too short")
+	closedErr           = errors.New("codec is closed")
+)
+
+type HXCodec int
+
+const (
+	//Frames are carried as they are, for testing the pipeline without a codec.
+	PassThrough HXCodec = iota
+	H264
+	H265
+)
+
+func (c HXCodec) String() string {
+	switch c {
+	case PassThrough:
+		return "PassThrough"
+	case H264:
+		return "H.264"
+	case H265:
+		return "H.265"
+	}
+	return "unknown"
+}
+
+type HXOptions struct {
+	//Size of the frames in pixels.
+	Width, Height int
+
+	//Target bitrate in bits per second, zero lets the codec choose.
+	Bitrate int
+
+	//Frames between key frames, zero lets the codec choose.
+	GOPSize int
+}
+
+//HXEncoder compresses raw frames into packets. Encode may buffer frames and return nil until it has a packet.
+type HXEncoder interface {
+	Encode(frame []byte, timecode int64) ([]byte, error)
+	Close() error
+}
+
+//HXDecoder turns packets from an HXEncoder back into raw frames. Decode may return nil until it has a frame.
+type HXDecoder interface {
+	Decode(packet []byte) (frame []byte, timecode int64, err error)
+	Close() error
+}
+
+//Constructors of the codecs registered with RegisterCodec.
+type codecImpl struct {
+	newEncoder func(HXOptions) (HXEncoder, error)
+	newDecoder func(HXOptions) (HXDecoder, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[HXCodec]codecImpl{
+		PassThrough: {
+			func(HXOptions) (HXEncoder, error) { return &passThroughEncoder{}, nil },
+			func(HXOptions) (HXDecoder, error) { return &passThroughDecoder{}, nil },
+		},
+	}
+)
+
+//RegisterCodec is the extension point for real codecs, for example a wrapper of a hardware encoder or of
+//FFmpeg. It replaces any earlier registration of codec, including PassThrough.
+func RegisterCodec(codec HXCodec, newEncoder func(HXOptions) (HXEncoder, error), newDecoder func(HXOptions) (HXDecoder, error)) {
+	codecsMu.Lock()
+	codecs[codec] = codecImpl{newEncoder, newDecoder}
+	codecsMu.Unlock()
+}
+
+//NewHXEncoder creates an encoder of the registered codec. If there is none or it fails, the encoder returns the
+//error from every call instead.
+func NewHXEncoder(codec HXCodec, opts HXOptions) HXEncoder {
+	codecsMu.RLock()
+	impl, ok := codecs[codec]
+	codecsMu.RUnlock()
+	if !ok || impl.newEncoder == nil {
+		return failedCodec{unsupportedCodecErr}
+	}
+
+	enc, err := impl.newEncoder(opts)
+	if err != nil {
+		return failedCodec{err}
+	}
+	return enc
+}
+
+//NewHXDecoder creates a decoder of the registered codec, see NewHXEncoder.
+func NewHXDecoder(codec HXCodec, opts HXOptions) HXDecoder {
+	codecsMu.RLock()
+	impl, ok := codecs[codec]
+	codecsMu.RUnlock()
+	if !ok || impl.newDecoder == nil {
+		return failedCodec{unsupportedCodecErr}
+	}
+
+	dec, err := impl.newDecoder(opts)
+	if err != nil {
+		return failedCodec{err}
+	}
+	return dec
+}
+
+//Stands in for a codec that could not be created.
+type failedCodec struct {
+	err error
+}
+
+func (c failedCodec) Encode([]byte, int64) ([]byte, error) {
+	return nil, c.err
+}
+
+func (c failedCodec) Decode([]byte) ([]byte, int64, error) {
+	return nil, 0, c.err
+}
+
+func (c failedCodec) Close() error {
+	return nil
+}
+
+//The pass through packets are the timecode, 8 bytes little endian, followed by a copy of the frame.
+const timecodeSize = 8
+
+type passThroughEncoder struct {
+	closed bool
+}
+
+func (e *passThroughEncoder) Encode(frame []byte, timecode int64) ([]byte, error) {
+	if e.closed {
+		return nil, closedErr
+	}
+
+	packet := make([]byte, timecodeSize+len(frame))
+	binary.LittleEndian.PutUint64(packet, uint64(timecode))
+	copy(packet[timecodeSize:], frame)
+	return packet, nil
+}
+
+func (e *passThroughEncoder) Close() error {
+	e.closed = true
+	return nil
+}
+
+type passThroughDecoder struct {
+	closed bool
+}
+
+func (d *passThroughDecoder) Decode(packet []byte) ([]byte, int64, error) {
+	if d.closed {
+		return nil, 0, closedErr
+	}
+	if len(packet) < timecodeSize {
+		return nil, 0, shortPacketErr
+	}
+
+	frame := append([]byte(nil), packet[timecodeSize:]...)
+	return frame, int64(binary.LittleEndian.Uint64(packet)), nil
+}
+
+func (d *passThroughDecoder) Close() error {
+	d.closed = true
+	return nil
+}
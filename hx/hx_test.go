@@ -0,0 +1,68 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package hx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPassThrough(t *testing.T) {
+	enc := NewHXEncoder(PassThrough, HXOptions{Width: 2, Height: 1})
+	dec := NewHXDecoder(PassThrough, HXOptions{Width: 2, Height: 1})
+
+	frame := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	packet, err := enc.Encode(frame, -42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame[0] = 99
+
+	got, tc, err := dec.Decode(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{1, 2, 3, 4, 5, 6, 7, 8}; !bytes.Equal(got, want) || tc != -42 {
+		t.Errorf("Expected %v at -42 but got %v at %d.", want, got, tc)
+	}
+
+	if _, _, err := dec.Decode(packet[:3]); err != shortPacketErr {
+		t.Errorf("Expected %v but got %v.", shortPacketErr, err)
+	}
+
+	enc.Close()
+	dec.Close()
+	if _, err := enc.Encode(frame, 0); err != closedErr {
+		t.Errorf("Expected %v but got %v.", closedErr, err)
+	}
+	if _, _, err := dec.Decode(packet); err != closedErr {
+		t.Errorf("Expected %v but got %v.", closedErr, err)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	if _, err := NewHXEncoder(H265, HXOptions{}).Encode(nil, 0); err != unsupportedCodecErr {
+		t.Errorf("Expected %v but got %v.", unsupportedCodecErr, err)
+	}
+	if _, _, err := NewHXDecoder(H265, HXOptions{}).Decode(nil); err != unsupportedCodecErr {
+		t.Errorf("Expected %v but got %v.", unsupportedCodecErr, err)
+	}
+
+	failed := errors.New("no hardware encoder")
+	RegisterCodec(H265, func(HXOptions) (HXEncoder, error) { return nil, failed }, nil)
+	defer func() {
+		codecsMu.Lock()
+		delete(codecs, H265)
+		codecsMu.Unlock()
+	}()
+
+	if _, err := NewHXEncoder(H265, HXOptions{}).Encode(nil, 0); err != failed {
+		t.Errorf("Expected %v but got %v.", failed, err)
+	}
+	if _, _, err := NewHXDecoder(H265, HXOptions{}).Decode(nil); err != unsupportedCodecErr {
+		t.Errorf("Expected %v but got %v.", unsupportedCodecErr, err)
+	}
+}
@@ -0,0 +1,125 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"encoding/xml"
+	"errors"
+	"runtime"
+	"sort"
+	"time"
+)
+
+var (
+	invalidProbeCountErr = errors.New("at least one probe is needed")
+	probeTimeoutErr      = errors.New("probe did not come back in time")
+)
+
+const (
+	//How long ProbeLatency waits for each probe to come back.
+	probeTimeout = 2 * time.Second
+
+	//How long each capture of ProbeLatency waits for metadata, in milliseconds.
+	probePollTimeout = 100
+)
+
+//RTTStats summarizes the round trip times measured by ProbeLatency.
+type RTTStats struct {
+	Probes             int
+	Min, Max, Avg, P95 time.Duration
+}
+
+func newRTTStats(rtts []time.Duration) RTTStats {
+	if len(rtts) == 0 {
+		return RTTStats{}
+	}
+
+	sorted := append([]time.Duration(nil), rtts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, rtt := range sorted {
+		sum += rtt
+	}
+	return RTTStats{
+		Probes: len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Avg:    sum / time.Duration(len(sorted)),
+		//The nearest rank, the smallest time at least 95% of the probes did not exceed.
+		P95: sorted[(len(sorted)*95+99)/100-1],
+	}
+}
+
+//The metadata that is sent around the loop. The session keeps probes of concurrent runs apart.
+type probeMetadata struct {
+	XMLName xml.Name `xml:"ndi_go_probe"`
+	Session int64    `xml:"session,attr"`
+	Seq     int      `xml:"seq,attr"`
+}
+
+//The parts of SendInstance and RecvInstance that ProbeLatency uses.
+type probeSender interface {
+	SendMetadata(mf *MetadataFrame)
+}
+
+type probeReceiver interface {
+	CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType
+	FreeMetadataV2(mf *MetadataFrame)
+}
+
+//ProbeLatency measures the round trip time of metadata from send to recv, which must receive what send sends,
+//directly or through other devices. It sends probes metadata frames one after another and waits up to two
+//seconds for each to come back. Video and audio that recv gets in the meantime is dropped.
+func ProbeLatency(recv *RecvInstance, send *SendInstance, probes int) (RTTStats, error) {
+	return probeLatency(recv, send, probes)
+}
+
+func probeLatency(recv probeReceiver, send probeSender, probes int) (RTTStats, error) {
+	if probes <= 0 {
+		return RTTStats{}, invalidProbeCountErr
+	}
+
+	session := sysClock.Now().UnixNano()
+	rtts := make([]time.Duration, 0, probes)
+	for seq := 0; seq < probes; seq++ {
+		b, err := xml.Marshal(probeMetadata{Session: session, Seq: seq})
+		if err != nil {
+			return RTTStats{}, err
+		}
+		data := append(b, 0)
+		mf := NewMetadataFrame()
+		mf.Data = &data[0]
+
+		sent := sysClock.Now()
+		send.SendMetadata(mf)
+		runtime.KeepAlive(data)
+
+		if err := awaitProbe(recv, session, seq, sent.Add(probeTimeout)); err != nil {
+			return newRTTStats(rtts), err
+		}
+		rtts = append(rtts, sysClock.Now().Sub(sent))
+	}
+	return newRTTStats(rtts), nil
+}
+
+//Captures from recv until the probe seq of session arrives or deadline passes.
+func awaitProbe(recv probeReceiver, session int64, seq int, deadline time.Time) error {
+	for sysClock.Now().Before(deadline) {
+		var mf MetadataFrame
+		switch recv.CaptureV2(nil, nil, &mf, probePollTimeout) {
+		case FrameTypeMetadata:
+			var probe probeMetadata
+			err := xml.Unmarshal([]byte(mf.ReadString()), &probe)
+			recv.FreeMetadataV2(&mf)
+			if err == nil && probe.Session == session && probe.Seq == seq {
+				return nil
+			}
+		case FrameTypeError:
+			return connectionLostErr
+		}
+	}
+	return probeTimeoutErr
+}
@@ -0,0 +1,83 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FlowingSPDG/ndi-go/nditest"
+)
+
+//A loop that delivers the metadata sent to it after the next delay, or loses it once the delays run out.
+type fakeProbeLoop struct {
+	clock   *nditest.FakeClock
+	delays  []time.Duration
+	pending []string
+}
+
+func (l *fakeProbeLoop) SendMetadata(mf *MetadataFrame) {
+	//Unrelated metadata arrives first.
+	l.pending = append(l.pending, `<ndi_tally on_program="true"/>`, mf.ReadString())
+}
+
+func (l *fakeProbeLoop) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	if len(l.pending) == 0 || len(l.delays) == 0 {
+		l.clock.Advance(time.Duration(timeoutInMs) * time.Millisecond)
+		return FrameTypeNone
+	}
+
+	data := append([]byte(l.pending[0]), 0)
+	l.pending = l.pending[1:]
+	//The probe is the second of each pair, its delay passes before it arrives.
+	if len(l.pending)%2 == 0 {
+		l.clock.Advance(l.delays[0])
+		l.delays = l.delays[1:]
+	}
+	mf.Data = &data[0]
+	return FrameTypeMetadata
+}
+
+func (l *fakeProbeLoop) FreeMetadataV2(mf *MetadataFrame) {
+	mf.Data = nil
+}
+
+func TestProbeLatency(t *testing.T) {
+	c := useFakeClock(t)
+	ms := time.Millisecond
+	loop := &fakeProbeLoop{clock: c, delays: []time.Duration{40 * ms, 10 * ms, 30 * ms, 20 * ms}}
+
+	stats, err := probeLatency(loop, loop, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (RTTStats{4, 10 * ms, 40 * ms, 25 * ms, 40 * ms}); stats != want {
+		t.Errorf("Expected %+v but got %+v.", want, stats)
+	}
+
+	//The fifth probe gets lost.
+	loop.delays = []time.Duration{15 * ms}
+	stats, err = probeLatency(loop, loop, 2)
+	if err != probeTimeoutErr || stats.Probes != 1 || stats.Max != 15*ms {
+		t.Errorf("Expected a timeout after one probe but got %+v (%v).", stats, err)
+	}
+
+	if _, err := probeLatency(loop, loop, 0); err != invalidProbeCountErr {
+		t.Errorf("Expected %v but got %v.", invalidProbeCountErr, err)
+	}
+}
+
+func TestRTTStatsPercentile(t *testing.T) {
+	rtts := make([]time.Duration, 20)
+	for i := range rtts {
+		rtts[i] = time.Duration(20-i) * time.Millisecond
+	}
+	if p95 := newRTTStats(rtts).P95; p95 != 19*time.Millisecond {
+		t.Errorf("Expected the 95th percentile of 1..20ms to be 19ms but got %v.", p95)
+	}
+	if stats := newRTTStats(nil); stats != (RTTStats{}) {
+		t.Errorf("Expected empty stats but got %+v.", stats)
+	}
+}
@@ -0,0 +1,138 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"time"
+)
+
+var noVideoErr = errors.New("no video has been received yet")
+
+//LipSyncCorrector pulls audio and video from a frame synchronizer and delays one against the other.
+//A positive offset delays video, a negative one delays audio. The returned frames are owned by Go and
+//must not be freed. It is not safe for concurrent use.
+type LipSyncCorrector struct {
+	fs     *FramesyncInstance
+	offset time.Duration
+	clock  clock
+
+	video []delayedVideoFrame
+
+	//Delayed audio, one slice per channel. Reset whenever the requested format changes.
+	audio                   [][]float32
+	sampleRate, numChannels int
+}
+
+type delayedVideoFrame struct {
+	captured time.Time
+	frame    *VideoFrameV2
+}
+
+func NewLipSyncCorrector(fs *FramesyncInstance, offsetMs int) *LipSyncCorrector {
+	return &LipSyncCorrector{
+		fs:     fs,
+		offset: time.Duration(offsetMs) * time.Millisecond,
+		clock:  sysClock,
+	}
+}
+
+//CaptureVideo returns the video frame that was current offset ago, or the current one if video is not delayed.
+func (c *LipSyncCorrector) CaptureVideo() (*VideoFrameV2, error) {
+	var vf VideoFrameV2
+	c.fs.CaptureVideo(&vf, FrameFormatProgressive)
+	if vf.Data == nil {
+		c.fs.FreeVideo(&vf)
+		return nil, noVideoErr
+	}
+	frame := vf.clone()
+	c.fs.FreeVideo(&vf)
+
+	if c.offset <= 0 {
+		return frame, nil
+	}
+	return c.delayVideo(c.clock.Now(), frame), nil
+}
+
+//Queues frame and returns the newest frame that is at least offset old. Until there is one the oldest
+//frame is repeated.
+func (c *LipSyncCorrector) delayVideo(now time.Time, frame *VideoFrameV2) *VideoFrameV2 {
+	c.video = append(c.video, delayedVideoFrame{now, frame})
+
+	due := 0
+	for i, f := range c.video {
+		if now.Sub(f.captured) >= c.offset {
+			due = i
+		}
+	}
+	for i := range c.video[:due] {
+		c.video[i] = delayedVideoFrame{}
+	}
+	c.video = c.video[due:]
+	return c.video[0].frame
+}
+
+//CaptureAudio returns exactly samples of audio per channel in the requested format, delayed by the
+//offset if audio is delayed.
+func (c *LipSyncCorrector) CaptureAudio(sampleRate, channels, samples int) (*AudioFrameV2, error) {
+	if sampleRate <= 0 || channels <= 0 || samples <= 0 {
+		return nil, invalidAudioFrameErr
+	}
+
+	var af AudioFrameV2
+	c.fs.CaptureAudio(&af, sampleRate, channels, samples)
+	captured := make([][]float32, channels)
+	for ch := range captured {
+		captured[ch] = make([]float32, samples)
+		copy(captured[ch], af.ReadChannel(ch))
+	}
+	c.fs.FreeAudio(&af)
+
+	if c.offset < 0 {
+		captured = c.delayAudio(captured, sampleRate)
+	}
+	return newPlanarAudioFrame(captured, sampleRate), nil
+}
+
+//Pushes the captured samples through the delay line and returns as many delayed ones.
+func (c *LipSyncCorrector) delayAudio(captured [][]float32, sampleRate int) [][]float32 {
+	if sampleRate != c.sampleRate || len(captured) != c.numChannels {
+		delay := int(-c.offset * time.Duration(sampleRate) / time.Second)
+		c.audio = make([][]float32, len(captured))
+		for ch := range c.audio {
+			c.audio[ch] = make([]float32, delay)
+		}
+		c.sampleRate, c.numChannels = sampleRate, len(captured)
+	}
+
+	out := make([][]float32, len(captured))
+	for ch, samples := range captured {
+		buf := append(c.audio[ch], samples...)
+		out[ch] = buf[:len(samples):len(samples)]
+		c.audio[ch] = append([]float32(nil), buf[len(samples):]...)
+	}
+	return out
+}
+
+//Builds a Go owned planar audio frame from per channel samples of equal length.
+func newPlanarAudioFrame(channels [][]float32, sampleRate int) *AudioFrameV2 {
+	af := NewAudioFrameV2()
+	af.SampleRate = int32(sampleRate)
+	af.NumChannels = int32(len(channels))
+	if len(channels) == 0 || len(channels[0]) == 0 {
+		return af
+	}
+
+	n := len(channels[0])
+	data := make([]float32, 0, n*len(channels))
+	for _, samples := range channels {
+		data = append(data, samples...)
+	}
+
+	af.NumSamples = int32(n)
+	af.ChannelStride = int32(n * 4)
+	af.Data = &data[0]
+	return af
+}
@@ -0,0 +1,54 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLipSyncVideoDelay(t *testing.T) {
+	c := NewLipSyncCorrector(nil, 100)
+	start := time.Unix(0, 0)
+
+	frames := make([]*VideoFrameV2, 10)
+	for i := range frames {
+		frames[i] = &VideoFrameV2{Timecode: int64(i)}
+	}
+
+	//One frame every 40ms, the newest one at least 100ms old is 3 frames (120ms) back.
+	for i, f := range frames {
+		out := c.delayVideo(start.Add(time.Duration(i)*40*time.Millisecond), f)
+
+		expected := 0
+		if i >= 3 {
+			expected = i - 3
+		}
+		if out.Timecode != int64(expected) {
+			t.Errorf("Frame %d: expected frame %d to be delivered but got %d.", i, expected, out.Timecode)
+		}
+	}
+}
+
+func TestLipSyncAudioDelay(t *testing.T) {
+	c := NewLipSyncCorrector(nil, -2)
+
+	//2ms at 1kHz is 2 samples of delay.
+	out := c.delayAudio([][]float32{{1, 2, 3}, {-1, -2, -3}}, 1000)
+	if expected := [][]float32{{0, 0, 1}, {0, 0, -1}}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("Expected %v but got %v.", expected, out)
+	}
+
+	out = c.delayAudio([][]float32{{4, 5, 6}, {-4, -5, -6}}, 1000)
+	if expected := [][]float32{{2, 3, 4}, {-2, -3, -4}}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("Expected %v but got %v.", expected, out)
+	}
+
+	af := newPlanarAudioFrame(out, 1000)
+	if af.NumChannels != 2 || af.NumSamples != 3 || !reflect.DeepEqual(af.ReadChannel(1), out[1]) {
+		t.Errorf("Unexpected audio frame %+v.", af)
+	}
+}
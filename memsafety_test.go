@@ -0,0 +1,395 @@
+//go:build memsafety
+// +build memsafety
+
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+//Regression suite for the code that reads or writes memory through unsafe pointers. Run it with
+//
+//	go test -tags memsafety -race -gcflags=all=-d=checkptr -run UnsafePaths .
+//
+//Reads are done on buffers of exactly the size the frame describes, so that checkptr reports any read past
+//their end. Writes are done on buffers surrounded by canary bytes, which are checked afterwards. Code that adds
+//a new unsafe conversion registers a case for it with registerUnsafePath.
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+var unsafePaths = make(map[string]func(t *testing.T))
+
+func registerUnsafePath(name string, fn func(t *testing.T)) {
+	if _, ok := unsafePaths[name]; ok {
+		panic("unsafe path registered twice: " + name)
+	}
+	unsafePaths[name] = fn
+}
+
+func TestUnsafePaths(t *testing.T) {
+	names := make([]string, 0, len(unsafePaths))
+	for name := range unsafePaths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, unsafePaths[name])
+	}
+}
+
+const (
+	canarySize = 64
+	canaryByte = 0xa5
+)
+
+//Returns n bytes surrounded by canaries and a function failing t if any canary was overwritten.
+func guardedBytes(t *testing.T, n int) ([]byte, func()) {
+	all := make([]byte, n+2*canarySize)
+	for i := range all {
+		all[i] = canaryByte
+	}
+	buf := all[canarySize : canarySize+n : canarySize+n]
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	return buf, func() {
+		t.Helper()
+		for i, b := range all {
+			if (i < canarySize || i >= canarySize+n) && b != canaryByte {
+				t.Fatalf("Canary at offset %d of a %d byte buffer was overwritten.", i-canarySize, n)
+			}
+		}
+	}
+}
+
+//Like guardedBytes for float32 samples.
+func guardedFloats(t *testing.T, n int) ([]float32, func()) {
+	buf, check := guardedBytes(t, n*4)
+	return unsafe.Slice((*float32)(unsafe.Pointer(&buf[0])), n), check
+}
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procVirtualAlloc   = kernel32.NewProc("VirtualAlloc")
+	procVirtualProtect = kernel32.NewProc("VirtualProtect")
+	procVirtualFree    = kernel32.NewProc("VirtualFree")
+)
+
+//Returns n bytes outside the Go heap, like memory owned by the SDK, which checkptr does not cover. They end right
+//before an inaccessible page, so that reading past their end faults. n is at most a page.
+func sdkBytes(t *testing.T, n int) (uintptr, []byte) {
+	const page = 4096
+	base, _, err := procVirtualAlloc.Call(0, 2*page, 0x3000 /* MEM_COMMIT|MEM_RESERVE */, 0x04 /* PAGE_READWRITE */)
+	if base == 0 {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { procVirtualFree.Call(base, 0, 0x8000 /* MEM_RELEASE */) })
+
+	var old uint32
+	if ok, _, err := procVirtualProtect.Call(base+page, page, 0x01 /* PAGE_NOACCESS */, uintptr(unsafe.Pointer(&old))); ok == 0 {
+		t.Fatal(err)
+	}
+
+	p := base + page - uintptr(n)
+	return p, unsafe.Slice((*byte)(unsafe.Pointer(p)), n)
+}
+
+//A frame of n samples per channel with a channel stride of stride samples.
+func planarFrame(data []float32, channels, n, stride int) *AudioFrameV2 {
+	af := NewAudioFrameV2()
+	af.NumChannels = int32(channels)
+	af.NumSamples = int32(n)
+	af.ChannelStride = int32(stride * 4)
+	af.Data = &data[0]
+	return af
+}
+
+func init() {
+	registerUnsafePath("goString", func(t *testing.T) {
+		for _, s := range []string{"", "a", "Kamera Süd (1)"} {
+			if got := goString(cString(s)); got != s {
+				t.Errorf("Expected %q but got %q.", s, got)
+			}
+		}
+	})
+
+	registerUnsafePath("MetadataFrame.ReadString", func(t *testing.T) {
+		//Exactly Length bytes without a terminator.
+		data := []byte("<a/>")
+		mf := MetadataFrame{Length: int32(len(data)), Data: &data[0]}
+		if s := mf.ReadString(); s != "<a/>" {
+			t.Errorf("Expected <a/> but got %q.", s)
+		}
+	})
+
+	registerUnsafePath("VideoFrameV2.ReadData", func(t *testing.T) {
+		const w, h = 6, 4
+		for _, format := range []struct {
+			fourCC [4]byte
+			size   int
+		}{
+			{FourCCTypeBGRA, w * 4 * h},
+			{FourCCTypeUYVY, w * 2 * h},
+			{FourCCTypeUYVA, w*2*h + w*h},
+		} {
+			data := make([]byte, format.size)
+			vf := NewVideoFrameV2()
+			vf.FourCC, vf.Xres, vf.Yres = format.fourCC, w, h
+			vf.LineStride = int32(format.size / h)
+			if format.fourCC == FourCCTypeUYVA {
+				vf.LineStride = w * 2
+			}
+			vf.Data = &data[0]
+
+			if d := vf.data(); len(d) != format.size {
+				t.Errorf("%s: Expected %d bytes but got %d.", format.fourCC, format.size, len(d))
+			}
+			vf.ReadData()
+			vf.clone()
+			HasTransparency(vf, 1)
+		}
+	})
+
+	registerUnsafePath("AudioFrameV2.ReadChannel", func(t *testing.T) {
+		//The last channel ends right after its samples, without the padding of the stride.
+		data := make([]float32, 2*8+5)
+		af := planarFrame(data, 3, 5, 8)
+		for ch := 0; ch < 3; ch++ {
+			if n := len(af.ReadChannel(ch)); n != 5 {
+				t.Errorf("Expected 5 samples in channel %d but got %d.", ch, n)
+			}
+		}
+		if af.ReadChannel(3) != nil {
+			t.Error("Expected no samples for a channel that does not exist.")
+		}
+
+		packed := planarFrame(make([]float32, 10), 2, 5, 5)
+		if n := len(packed.ReadSamples()); n != 10 {
+			t.Errorf("Expected 10 samples but got %d.", n)
+		}
+	})
+
+	registerUnsafePath("AudioFrame.ToV3", func(t *testing.T) {
+		data := make([]float32, 10)
+		v2 := planarFrame(data, 2, 5, 5).ToV3().ToV2()
+		if n := len(v2.ReadSamples()); n != 10 {
+			t.Errorf("Expected 10 samples but got %d.", n)
+		}
+	})
+
+	registerUnsafePath("AudioFrameV3.ToV2", func(t *testing.T) {
+		//The last channel ends right after its samples.
+		data := make([]float32, 8+5)
+		af := NewAudioFrameV3()
+		af.NumChannels, af.NumSamples, af.ChannelStride = 2, 5, 8*4
+		af.Data = (*byte)(unsafe.Pointer(&data[0]))
+
+		v2 := af.ToV2()
+		for ch := 0; ch < 2; ch++ {
+			if n := len(v2.ReadChannel(ch)); n != 5 {
+				t.Errorf("Expected 5 samples in channel %d but got %d.", ch, n)
+			}
+		}
+
+		af.FourCC = 0
+		if af.ToV2() != nil {
+			t.Error("Expected no AudioFrameV2 for audio that is not FLTP.")
+		}
+	})
+
+	//The SDK reads these frames through the pointers it is given, so their fields must sit where its structs have
+	//them. The offsets are those of the 64-bit SDK.
+	registerUnsafePath("audio frame layouts", func(t *testing.T) {
+		if unsafe.Sizeof(uintptr(0)) != 8 {
+			t.Skip("The offsets are only known for 64-bit builds.")
+		}
+
+		var v3 AudioFrameV3
+		var i16 AudioFrameInterleaved16s
+		var i32 AudioFrameInterleaved32s
+		var f32 AudioFrameInterleaved32f
+		layouts := []struct {
+			name          string
+			offsets, sdk  []uintptr
+			size, sdkSize uintptr
+		}{
+			{"AudioFrameV3",
+				[]uintptr{unsafe.Offsetof(v3.Timecode), unsafe.Offsetof(v3.FourCC), unsafe.Offsetof(v3.Data), unsafe.Offsetof(v3.ChannelStride), unsafe.Offsetof(v3.Metadata), unsafe.Offsetof(v3.Timestamp)},
+				[]uintptr{16, 24, 32, 40, 48, 56}, unsafe.Sizeof(v3), 64},
+			{"AudioFrameInterleaved16s",
+				[]uintptr{unsafe.Offsetof(i16.Timecode), unsafe.Offsetof(i16.ReferenceLevel), unsafe.Offsetof(i16.Data)},
+				[]uintptr{16, 24, 32}, unsafe.Sizeof(i16), 40},
+			{"AudioFrameInterleaved32s",
+				[]uintptr{unsafe.Offsetof(i32.Timecode), unsafe.Offsetof(i32.ReferenceLevel), unsafe.Offsetof(i32.Data)},
+				[]uintptr{16, 24, 32}, unsafe.Sizeof(i32), 40},
+			{"AudioFrameInterleaved32f",
+				[]uintptr{unsafe.Offsetof(f32.Timecode), unsafe.Offsetof(f32.Data)},
+				[]uintptr{16, 24}, unsafe.Sizeof(f32), 32},
+		}
+		for _, l := range layouts {
+			for i := range l.sdk {
+				if l.offsets[i] != l.sdk[i] {
+					t.Errorf("Expected the fields of %s at offsets %v but got %v.", l.name, l.sdk, l.offsets)
+					break
+				}
+			}
+			if l.size != l.sdkSize {
+				t.Errorf("Expected %s to be %d bytes but got %d.", l.name, l.sdkSize, l.size)
+			}
+		}
+
+		//The SDK reads NumChannels*NumSamples samples, so a frame with samples needs data.
+		if err := checkInterleavedAudio(2, 4, false); err != invalidAudioFrameErr {
+			t.Errorf("Expected %v but got %v.", invalidAudioFrameErr, err)
+		}
+	})
+
+	registerUnsafePath("RecvInstance.takeString", func(t *testing.T) {
+		const s = "Kamera Süd (1)"
+		p, buf := sdkBytes(t, len(s)+1)
+		copy(buf, s)
+
+		saved := funcPtrs
+		defer func() { funcPtrs = saved }()
+		var freed uintptr
+		funcPtrs = &ndiLIBv5{NDIlibRecvFreeString: syscall.NewCallback(func(inst, p uintptr) uintptr {
+			freed = p
+			return 0
+		})}
+
+		var inst RecvInstance
+		if got := inst.takeString(p); got != s {
+			t.Errorf("Expected %q but got %q.", s, got)
+		}
+		if freed != p {
+			t.Error("Expected the string to be handed back to the SDK.")
+		}
+		if got := inst.takeString(0); got != "" {
+			t.Errorf("Expected an empty string for NULL but got %q.", got)
+		}
+	})
+
+	registerUnsafePath("copySources", func(t *testing.T) {
+		sources := []Source{NewSource("CAM1 (Chan 1)", "10.0.0.1:5961"), NewSource("CAM2 (Chan 1)", "")}
+		p, buf := sdkBytes(t, len(sources)*int(unsafe.Sizeof(Source{})))
+		copy(unsafe.Slice((*Source)(unsafe.Pointer(&buf[0])), len(sources)), sources)
+
+		copied := copySources(p, uint32(len(sources)))
+		if len(copied) != len(sources) {
+			t.Fatalf("Expected %d sources but got %d.", len(sources), len(copied))
+		}
+		for i, s := range copied {
+			if s.Name() != sources[i].Name() || s.Address() != sources[i].Address() || s.name == sources[i].name {
+				t.Errorf("Expected a copy of %q at %q but got %q at %q.", sources[i].Name(), sources[i].Address(), s.Name(), s.Address())
+			}
+		}
+		if copySources(0, 2) != nil {
+			t.Error("Expected no sources for NULL.")
+		}
+
+		//The strings are only referenced from memory the garbage collector does not scan.
+		runtime.KeepAlive(sources)
+	})
+
+	registerUnsafePath("FadeIn", func(t *testing.T) {
+		data, check := guardedFloats(t, 8+6)
+		for i := range data {
+			data[i] = 1
+		}
+		af := planarFrame(data, 2, 6, 8)
+		if err := FadeIn(af, 6); err != nil {
+			t.Fatal(err)
+		}
+		if err := FadeOut(af, 6); err != nil {
+			t.Fatal(err)
+		}
+		check()
+	})
+
+	registerUnsafePath("NoiseGate.Process", func(t *testing.T) {
+		data, check := guardedFloats(t, 8+6)
+		for i := range data {
+			data[i] = float32(math.Sin(float64(i))) / 100
+		}
+		if err := NewNoiseGate(-20, 1, 0, 1).Process(planarFrame(data, 2, 6, 8)); err != nil {
+			t.Fatal(err)
+		}
+		check()
+	})
+
+	registerUnsafePath("BlendFrames", func(t *testing.T) {
+		//Rows are padded to a stride of 16 bytes.
+		const w, h, stride = 3, 2, 16
+		frames := make([]*VideoFrameV2, 2)
+		for i := range frames {
+			data := make([]byte, stride*h)
+			vf := NewVideoFrameV2()
+			vf.FourCC, vf.Xres, vf.Yres, vf.LineStride = FourCCTypeBGRA, w, h, stride
+			vf.Data = &data[0]
+			frames[i] = vf
+		}
+
+		out, err := BlendFrames(frames, []float32{0.5, 0.5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(out.data()); n != w*4*h {
+			t.Errorf("Expected %d bytes of blended data but got %d.", w*4*h, n)
+		}
+	})
+
+	registerUnsafePath("DynamicsProcessor.Process", func(t *testing.T) {
+		data, check := guardedFloats(t, 2*8)
+		for i := range data {
+			data[i] = float32(math.Sin(float64(i)))
+		}
+		if err := NewLimiter(-6, 1, 10).Process(planarFrame(data, 2, 6, 8)); err != nil {
+			t.Fatal(err)
+		}
+		check()
+	})
+
+	registerUnsafePath("MuteController.ProcessAudio", func(t *testing.T) {
+		data, check := guardedFloats(t, 2*8)
+		m := NewMuteController()
+		m.SetAudioMuted(true)
+		m.ProcessAudio(planarFrame(data, 2, 6, 8))
+		check()
+	})
+
+	registerUnsafePath("ExternalVideoFrame.SetExternalData", func(t *testing.T) {
+		buf, check := guardedBytes(t, 4*4*2)
+		vf := NewExternalVideoFrame()
+		vf.FourCC, vf.Xres, vf.Yres, vf.LineStride = FourCCTypeBGRA, 4, 2, 16
+		vf.SetExternalData(unsafe.Pointer(&buf[0]), len(buf))
+
+		if err := vf.CheckSize(); err != nil {
+			t.Fatal(err)
+		}
+		vf.Yres = 3
+		if err := vf.CheckSize(); err != externalDataTooSmallErr {
+			t.Errorf("Expected %v but got %v.", externalDataTooSmallErr, err)
+		}
+		check()
+	})
+
+	registerUnsafePath("VideoFrameV2.AttachAncillary", func(t *testing.T) {
+		vf := NewVideoFrameV2()
+		vf.Metadata = cString("<meta/>")
+		if err := vf.AttachAncillary([]Ancillary{{Type: "cc", Payload: []byte{1, 2}}}); err != nil {
+			t.Fatal(err)
+		}
+		if anc, err := vf.Ancillary(); err != nil || len(anc) != 1 {
+			t.Errorf("Expected one ancillary packet but got %v (%v).", anc, err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "sync"
+
+//MetadataRingBuffer keeps copies of the most recent metadata frames, for example to match late PTZ responses to the
+//commands they answer. Once full, every push drops the oldest frame. It is safe for concurrent use.
+type MetadataRingBuffer struct {
+	mu     sync.Mutex
+	frames []*MetadataFrame
+	next   int
+	full   bool
+}
+
+//NewMetadataRingBuffer returns a buffer holding up to capacity frames, at least one.
+func NewMetadataRingBuffer(capacity int) *MetadataRingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MetadataRingBuffer{frames: make([]*MetadataFrame, capacity)}
+}
+
+//Push stores a copy of mf, so mf may be freed right after.
+func (b *MetadataRingBuffer) Push(mf *MetadataFrame) {
+	c := &MetadataFrame{Timecode: mf.Timecode, Data: cString(mf.ReadString())}
+
+	b.mu.Lock()
+	b.frames[b.next] = c
+	b.next = (b.next + 1) % len(b.frames)
+	b.full = b.full || b.next == 0
+	b.mu.Unlock()
+}
+
+//Last returns up to n of the most recent frames, oldest first.
+func (b *MetadataRingBuffer) Last(n int) []*MetadataFrame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n > b.len() {
+		n = b.len()
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	out := make([]*MetadataFrame, n)
+	for i := range out {
+		out[i] = b.at(b.len() - n + i)
+	}
+	return out
+}
+
+//FindByTimecode returns the most recent frame with the timecode tc.
+func (b *MetadataRingBuffer) FindByTimecode(tc int64) (*MetadataFrame, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := b.len() - 1; i >= 0; i-- {
+		if mf := b.at(i); mf.Timecode == tc {
+			return mf, true
+		}
+	}
+	return nil, false
+}
+
+func (b *MetadataRingBuffer) len() int {
+	if b.full {
+		return len(b.frames)
+	}
+	return b.next
+}
+
+//Returns the i-th oldest frame.
+func (b *MetadataRingBuffer) at(i int) *MetadataFrame {
+	if !b.full {
+		return b.frames[i]
+	}
+	return b.frames[(b.next+i)%len(b.frames)]
+}
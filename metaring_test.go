@@ -0,0 +1,54 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataRingBuffer(t *testing.T) {
+	b := NewMetadataRingBuffer(3)
+	if b.Last(2) != nil {
+		t.Error("Expected no frames in an empty buffer.")
+	}
+	if _, ok := b.FindByTimecode(0); ok {
+		t.Error("Expected no match in an empty buffer.")
+	}
+
+	readAll := func(frames []*MetadataFrame) []string {
+		var s []string
+		for _, mf := range frames {
+			s = append(s, mf.ReadString())
+		}
+		return s
+	}
+
+	for i := 1; i <= 5; i++ {
+		data := []byte(fmt.Sprintf("<ptz id=\"%d\"/>\x00", i))
+		mf := NewMetadataFrame()
+		mf.Timecode = int64(i * 100)
+		mf.Data = &data[0]
+		b.Push(mf)
+
+		//The buffer holds a copy.
+		data[1] = 'X'
+	}
+
+	if got := readAll(b.Last(10)); !reflect.DeepEqual(got, []string{`<ptz id="3"/>`, `<ptz id="4"/>`, `<ptz id="5"/>`}) {
+		t.Errorf("Unexpected frames %q.", got)
+	}
+	if got := readAll(b.Last(1)); !reflect.DeepEqual(got, []string{`<ptz id="5"/>`}) {
+		t.Errorf("Unexpected last frame %q.", got)
+	}
+
+	if mf, ok := b.FindByTimecode(400); !ok || mf.ReadString() != `<ptz id="4"/>` {
+		t.Errorf("Expected the frame with timecode 400 but got %v.", mf)
+	}
+	if _, ok := b.FindByTimecode(200); ok {
+		t.Error("Expected the frame with timecode 200 to be dropped.")
+	}
+}
@@ -0,0 +1,70 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+)
+
+const (
+	//How fast a held peak falls back when the signal gets quieter.
+	meterFallDBPerSec = 20
+
+	//The level reported for silence.
+	meterFloorDBFS = -96
+)
+
+//NDIAudioMeter measures the per channel peak level of received audio for sending back to the source with
+//RecvInstance.SendMetadata, so that a sender can show what its receivers hear. Peaks are held and fall back at
+//20dB per second like on a hardware meter. It is not safe for concurrent use.
+type NDIAudioMeter struct {
+	held []float64
+}
+
+type audioMeterMetadata struct {
+	XMLName  xml.Name            `xml:"ndi_audio_meter"`
+	Channels []audioMeterChannel `xml:"channel"`
+}
+
+type audioMeterChannel struct {
+	Peak string `xml:"peak,attr"`
+}
+
+//Feed measures af and returns the held peaks of all channels in dBFS as an XML metadata string, e.g.
+//<ndi_audio_meter><channel peak="-6.0"></channel><channel peak="-12.0"></channel></ndi_audio_meter>.
+//Returns an empty string for frames without audio.
+func (m *NDIAudioMeter) Feed(af *AudioFrameV2) string {
+	if af == nil || af.SampleRate <= 0 || af.NumChannels <= 0 || af.NumSamples <= 0 || af.Data == nil {
+		return ""
+	}
+
+	if len(m.held) != int(af.NumChannels) {
+		m.held = make([]float64, af.NumChannels)
+		for ch := range m.held {
+			m.held[ch] = meterFloorDBFS
+		}
+	}
+	fall := meterFallDBPerSec * float64(af.NumSamples) / float64(af.SampleRate)
+
+	md := audioMeterMetadata{Channels: make([]audioMeterChannel, af.NumChannels)}
+	for ch := range m.held {
+		var peak float64
+		for _, s := range af.ReadChannel(ch) {
+			peak = math.Max(peak, math.Abs(float64(s)))
+		}
+		level := math.Max(20*math.Log10(peak), meterFloorDBFS)
+
+		m.held[ch] = math.Max(level, math.Max(m.held[ch]-fall, meterFloorDBFS))
+		md.Channels[ch].Peak = strconv.FormatFloat(m.held[ch], 'f', 1, 64)
+	}
+
+	b, err := xml.Marshal(md)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,33 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestNDIAudioMeter(t *testing.T) {
+	frame := func(left, right float32, samples int) *AudioFrameV2 {
+		l, r := make([]float32, samples), make([]float32, samples)
+		l[0], r[samples-1] = left, -right
+		return newPlanarAudioFrame([][]float32{l, r}, 48000)
+	}
+
+	var m NDIAudioMeter
+	tests := []struct {
+		af       *AudioFrameV2
+		expected string
+	}{
+		{frame(0.5, 0.25, 480), `<ndi_audio_meter><channel peak="-6.0"></channel><channel peak="-12.0"></channel></ndi_audio_meter>`},
+		//100ms of silence lets the held peaks fall by 2dB.
+		{frame(0, 0, 4800), `<ndi_audio_meter><channel peak="-8.0"></channel><channel peak="-14.0"></channel></ndi_audio_meter>`},
+		{frame(0, 1, 480), `<ndi_audio_meter><channel peak="-8.2"></channel><channel peak="0.0"></channel></ndi_audio_meter>`},
+		{NewAudioFrameV2(), ""},
+	}
+
+	for i, test := range tests {
+		if xml := m.Feed(test.af); xml != test.expected {
+			t.Errorf("Frame %d: Expected %s but got %s.", i, test.expected, xml)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//RecvMetrics is a snapshot of the capture statistics of one receiver. Total, Dropped, Queue and Connections
+//come from the receiver itself, FPS, LastFrameAge and EstimatedBitrate from whoever captures from it.
+type RecvMetrics struct {
+	Receiver, Source string
+
+	Total, Dropped RecvPerformance
+	Queue          RecvQueue
+	Connections    int
+	FPS            float64
+	LastFrameAge   time.Duration
+
+	//An ESTIMATE of the video bandwidth in bits per second, see EstimateRecvBitrate and SetEstimatedBitrate.
+	EstimatedBitrate float64
+}
+
+//SetEstimatedBitrate fills EstimatedBitrate from the size of the received frames and FPS, see EstimateRecvBitrate.
+func (m *RecvMetrics) SetEstimatedBitrate(frameSize int, bandwidth RecvBandwidth) {
+	m.EstimatedBitrate = EstimateRecvBitrate(frameSize, m.FPS, bandwidth)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+//WriteOpenMetrics writes the metrics in the OpenMetrics text format, which Prometheus can scrape.
+func WriteOpenMetrics(w io.Writer, metrics []RecvMetrics) error {
+	bw := bufio.NewWriter(w)
+
+	family := func(name, typ, help string, value func(m *RecvMetrics, emit func(extraLabels string, v float64))) {
+		fmt.Fprintf(bw, "# TYPE %s %s\n# HELP %s %s\n", name, typ, name, help)
+
+		sample := name
+		if typ == "counter" {
+			sample += "_total"
+		}
+		for i := range metrics {
+			m := &metrics[i]
+			labels := fmt.Sprintf(`receiver="%s",source="%s"`, labelEscaper.Replace(m.Receiver), labelEscaper.Replace(m.Source))
+			value(m, func(extra string, v float64) {
+				fmt.Fprintf(bw, "%s{%s%s} %s\n", sample, labels, extra, strconv.FormatFloat(v, 'g', -1, 64))
+			})
+		}
+	}
+
+	perType := func(p func(m *RecvMetrics) (video, audio, metadata int64)) func(*RecvMetrics, func(string, float64)) {
+		return func(m *RecvMetrics, emit func(string, float64)) {
+			video, audio, metadata := p(m)
+			emit(`,type="video"`, float64(video))
+			emit(`,type="audio"`, float64(audio))
+			emit(`,type="metadata"`, float64(metadata))
+		}
+	}
+
+	family("ndi_recv_frames", "counter", "Frames received.", perType(func(m *RecvMetrics) (int64, int64, int64) {
+		return m.Total.VideoFrames, m.Total.AudioFrames, m.Total.MetadataFrames
+	}))
+	family("ndi_recv_dropped_frames", "counter", "Frames dropped.", perType(func(m *RecvMetrics) (int64, int64, int64) {
+		return m.Dropped.VideoFrames, m.Dropped.AudioFrames, m.Dropped.MetadataFrames
+	}))
+	family("ndi_recv_queue_depth", "gauge", "Frames waiting to be captured.", perType(func(m *RecvMetrics) (int64, int64, int64) {
+		return int64(m.Queue.VideoFrames), int64(m.Queue.AudioFrames), int64(m.Queue.MetadataFrames)
+	}))
+	family("ndi_recv_fps", "gauge", "Video frames captured per second.", func(m *RecvMetrics, emit func(string, float64)) {
+		emit("", m.FPS)
+	})
+	family("ndi_recv_connections", "gauge", "Connections to the source.", func(m *RecvMetrics, emit func(string, float64)) {
+		emit("", float64(m.Connections))
+	})
+	family("ndi_recv_last_frame_age_seconds", "gauge", "Time since the last frame was captured.", func(m *RecvMetrics, emit func(string, float64)) {
+		emit("", m.LastFrameAge.Seconds())
+	})
+	family("ndi_recv_estimated_video_bits_per_second", "gauge", "Estimated video bandwidth, not measured.", func(m *RecvMetrics, emit func(string, float64)) {
+		emit("", m.EstimatedBitrate)
+	})
+
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
@@ -0,0 +1,63 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const openMetricsGolden = `# TYPE ndi_recv_frames counter
+# HELP ndi_recv_frames Frames received.
+ndi_recv_frames_total{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="video"} 1800
+ndi_recv_frames_total{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="audio"} 1500
+ndi_recv_frames_total{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="metadata"} 3
+# TYPE ndi_recv_dropped_frames counter
+# HELP ndi_recv_dropped_frames Frames dropped.
+ndi_recv_dropped_frames_total{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="video"} 2
+ndi_recv_dropped_frames_total{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="audio"} 0
+ndi_recv_dropped_frames_total{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="metadata"} 0
+# TYPE ndi_recv_queue_depth gauge
+# HELP ndi_recv_queue_depth Frames waiting to be captured.
+ndi_recv_queue_depth{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="video"} 1
+ndi_recv_queue_depth{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="audio"} 0
+ndi_recv_queue_depth{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)",type="metadata"} 0
+# TYPE ndi_recv_fps gauge
+# HELP ndi_recv_fps Video frames captured per second.
+ndi_recv_fps{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)"} 59.94
+# TYPE ndi_recv_connections gauge
+# HELP ndi_recv_connections Connections to the source.
+ndi_recv_connections{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)"} 1
+# TYPE ndi_recv_last_frame_age_seconds gauge
+# HELP ndi_recv_last_frame_age_seconds Time since the last frame was captured.
+ndi_recv_last_frame_age_seconds{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)"} 0.0167
+# TYPE ndi_recv_estimated_video_bits_per_second gauge
+# HELP ndi_recv_estimated_video_bits_per_second Estimated video bandwidth, not measured.
+ndi_recv_estimated_video_bits_per_second{receiver="cam1",source="STUDIO (Cam \"A\" \\ 1)"} 1.325776896e+08
+# EOF
+`
+
+func TestWriteOpenMetrics(t *testing.T) {
+	metrics := []RecvMetrics{{
+		Receiver:     "cam1",
+		Source:       `STUDIO (Cam "A" \ 1)`,
+		Total:        RecvPerformance{1800, 1500, 3},
+		Dropped:      RecvPerformance{VideoFrames: 2},
+		Queue:        RecvQueue{VideoFrames: 1},
+		Connections:  1,
+		FPS:          59.94,
+		LastFrameAge: 16700 * time.Microsecond,
+	}}
+	metrics[0].SetEstimatedBitrate(1920*1080*2, RecvBandwidthHighest)
+
+	var b strings.Builder
+	if err := WriteOpenMetrics(&b, metrics); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != openMetricsGolden {
+		t.Errorf("Unexpected output:\n%s", b.String())
+	}
+}
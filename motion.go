@@ -0,0 +1,163 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "image"
+
+//Edge length of the square blocks that MotionDetector compares, in pixels.
+const motionBlockSize = 16
+
+//MotionDetector finds the regions that changed between consecutive frames of a stream by comparing the mean
+//absolute difference of the luma of 16x16 pixel blocks. It is not safe for concurrent use.
+type MotionDetector struct {
+	threshold int
+
+	prev          []byte
+	width, height int
+}
+
+//NewMotionDetector returns a detector that reports blocks whose mean absolute luma difference exceeds threshold,
+//on a scale of 0 to 255.
+func NewMotionDetector(threshold int) *MotionDetector {
+	return &MotionDetector{threshold: threshold}
+}
+
+//Detect compares vf to the previous frame and returns the bounding rectangles of connected moving blocks, in
+//pixels. The first frame, frames whose resolution changed and frames in an unsupported format (BGRA, BGRX and
+//UYVY are supported) report no motion.
+func (d *MotionDetector) Detect(vf *VideoFrameV2) []image.Rectangle {
+	cur := frameLuma(vf)
+	if cur == nil {
+		return nil
+	}
+
+	prev := d.prev
+	width, height := int(vf.Xres), int(vf.Yres)
+	sameSize := width == d.width && height == d.height
+	d.prev, d.width, d.height = cur, width, height
+	if !sameSize {
+		return nil
+	}
+
+	cols := (width + motionBlockSize - 1) / motionBlockSize
+	rows := (height + motionBlockSize - 1) / motionBlockSize
+	moving := make([]bool, cols*rows)
+	for by := 0; by < rows; by++ {
+		for bx := 0; bx < cols; bx++ {
+			moving[by*cols+bx] = d.blockMoved(prev, cur, bx, by)
+		}
+	}
+
+	return motionRegions(moving, cols, rows, width, height)
+}
+
+func (d *MotionDetector) blockMoved(prev, cur []byte, bx, by int) bool {
+	x0, y0 := bx*motionBlockSize, by*motionBlockSize
+	x1, y1 := minInt(x0+motionBlockSize, d.width), minInt(y0+motionBlockSize, d.height)
+
+	var sum int
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			diff := int(cur[y*d.width+x]) - int(prev[y*d.width+x])
+			if diff < 0 {
+				diff = -diff
+			}
+			sum += diff
+		}
+	}
+	return sum > d.threshold*(x1-x0)*(y1-y0)
+}
+
+//Joins 4-connected moving blocks and returns their bounding rectangles, clipped to the frame.
+func motionRegions(moving []bool, cols, rows, width, height int) []image.Rectangle {
+	var regions []image.Rectangle
+	visited := make([]bool, len(moving))
+	for start := range moving {
+		if !moving[start] || visited[start] {
+			continue
+		}
+
+		var r image.Rectangle
+		stack := []int{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			bx, by := i%cols, i/cols
+			block := image.Rect(bx*motionBlockSize, by*motionBlockSize, (bx+1)*motionBlockSize, (by+1)*motionBlockSize)
+			r = r.Union(block)
+
+			for _, n := range [][2]int{{bx - 1, by}, {bx + 1, by}, {bx, by - 1}, {bx, by + 1}} {
+				if n[0] < 0 || n[0] >= cols || n[1] < 0 || n[1] >= rows {
+					continue
+				}
+				j := n[1]*cols + n[0]
+				if moving[j] && !visited[j] {
+					visited[j] = true
+					stack = append(stack, j)
+				}
+			}
+		}
+		regions = append(regions, r.Intersect(image.Rect(0, 0, width, height)))
+	}
+	return regions
+}
+
+//Returns a copy of the luma of every pixel of vf, or nil for unsupported frames.
+func frameLuma(vf *VideoFrameV2) []byte {
+	if vf == nil || vf.Xres <= 0 || vf.Yres <= 0 {
+		return nil
+	}
+	width, height := int(vf.Xres), int(vf.Yres)
+
+	var (
+		bytesPerPixel int
+		lumaAt        func(row []byte, x int) byte
+	)
+	switch vf.FourCC {
+	case FourCCTypeBGRA, FourCCTypeBGRX:
+		bytesPerPixel = 4
+		lumaAt = func(row []byte, x int) byte {
+			b, g, r := int(row[x*4]), int(row[x*4+1]), int(row[x*4+2])
+			return byte((r + 2*g + b) / 4)
+		}
+	case FourCCTypeUYVY:
+		bytesPerPixel = 2
+		lumaAt = func(row []byte, x int) byte {
+			return row[x*2+1]
+		}
+	default:
+		return nil
+	}
+
+	data := vf.data()
+	if data == nil || int(vf.LineStride) < width*bytesPerPixel {
+		return nil
+	}
+
+	luma := make([]byte, width*height)
+	for y := 0; y < height; y++ {
+		row := data[y*int(vf.LineStride):]
+		for x := 0; x < width; x++ {
+			luma[y*width+x] = lumaAt(row, x)
+		}
+	}
+	return luma
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
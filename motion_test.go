@@ -0,0 +1,50 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestMotionDetector(t *testing.T) {
+	still := func(x, y int) byte { return 20 }
+	//A bright object spanning two blocks, and a one pixel flicker below the threshold.
+	moved := func(x, y int) byte {
+		switch {
+		case x >= 20 && x < 40 && y >= 4 && y < 12:
+			return 220
+		case x == 70 && y == 40:
+			return 40
+		}
+		return 20
+	}
+
+	d := NewMotionDetector(10)
+	tests := []struct {
+		name     string
+		pixel    func(x, y int) byte
+		expected []image.Rectangle
+	}{
+		{"first frame", still, nil},
+		{"no change", still, nil},
+		{"motion", moved, []image.Rectangle{image.Rect(16, 0, 48, 16)}},
+		{"motion back", still, []image.Rectangle{image.Rect(16, 0, 48, 16)}},
+	}
+
+	for _, test := range tests {
+		vf, _ := newTestVideoFrame(FourCCTypeBGRX, 80, 50, 4, test.pixel)
+		if regions := d.Detect(vf); !reflect.DeepEqual(regions, test.expected) {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.expected, regions)
+		}
+	}
+
+	//A resolution change is not motion.
+	vf, _ := newTestVideoFrame(FourCCTypeBGRX, 40, 40, 4, moved)
+	if regions := d.Detect(vf); regions != nil {
+		t.Errorf("Expected no motion after a resolution change but got %v.", regions)
+	}
+}
@@ -0,0 +1,166 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "sync"
+
+//MuteEvent reports a change of the mute state of a MuteController.
+type MuteEvent struct {
+	VideoMuted, AudioMuted bool
+}
+
+//MuteController mutes audio and video on the send path without interrupting the stream. Pass every outgoing
+//frame through it: muted video is replaced by black frames of the same format and timing, muted audio by
+//silence. Audio changes are ramped linearly over one frame to avoid clicks.
+type MuteController struct {
+	//Called with the new state whenever it changes.
+	OnChange func(MuteEvent)
+
+	//If set, state changes are also published here.
+	Bus *EventBus
+
+	mu         sync.Mutex
+	videoMuted bool
+	audioMuted bool
+
+	//The gain the last audio frame ended with.
+	audioGain float32
+
+	black       []byte
+	blackFormat VideoFormat
+	blackStride int32
+}
+
+func NewMuteController() *MuteController {
+	return &MuteController{audioGain: 1}
+}
+
+func (m *MuteController) SetVideoMuted(muted bool) {
+	m.mu.Lock()
+	changed := m.videoMuted != muted
+	m.videoMuted = muted
+	ev := MuteEvent{m.videoMuted, m.audioMuted}
+	m.mu.Unlock()
+
+	if changed {
+		m.notify(ev)
+	}
+}
+
+func (m *MuteController) SetAudioMuted(muted bool) {
+	m.mu.Lock()
+	changed := m.audioMuted != muted
+	m.audioMuted = muted
+	ev := MuteEvent{m.videoMuted, m.audioMuted}
+	m.mu.Unlock()
+
+	if changed {
+		m.notify(ev)
+	}
+}
+
+func (m *MuteController) notify(ev MuteEvent) {
+	if m.OnChange != nil {
+		m.OnChange(ev)
+	}
+	m.Bus.Publish(ev)
+}
+
+//ProcessVideo returns the frame to send in place of vf. While video is muted that is a black frame with the
+//format, timecode and metadata of vf, otherwise vf itself.
+func (m *MuteController) ProcessVideo(vf *VideoFrameV2) *VideoFrameV2 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.videoMuted {
+		return vf
+	}
+
+	if f := vf.Format(); m.black == nil || f != m.blackFormat || vf.LineStride != m.blackStride {
+		m.black = blackVideoData(vf)
+		m.blackFormat, m.blackStride = f, vf.LineStride
+	}
+
+	black := *vf
+	if len(m.black) != 0 {
+		black.Data = &m.black[0]
+	}
+	return &black
+}
+
+//Returns a black picture in the format of vf, with the alpha channel opaque. Data of formats this package has no
+//FourCC for is left zero.
+func blackVideoData(vf *VideoFrameV2) []byte {
+	n := vf.dataSize()
+	if n <= 0 {
+		return nil
+	}
+	data := make([]byte, n)
+	stride, height := int(vf.LineStride), int(vf.Yres)
+	luma := stride * height
+
+	switch vf.FourCC {
+	case FourCCTypeBGRA, FourCCTypeBGRX, FourCCTypeRGBA, FourCCTypeRGBX:
+		fillPattern(data, 0, 0, 0, 255)
+	case FourCCTypeUYVY, FourCCTypeUYVA:
+		fillPattern(data[:luma], 128, 16)
+		fillPattern(data[luma:], 255)
+	case FourCCTypeYV12, FourCCTypeI420, FourCCTypeNV12:
+		fillPattern(data[:luma], 16)
+		fillPattern(data[luma:], 128)
+	case FourCCTypeP216, FourCCTypePA16:
+		//Little endian 16-bit values, 16<<8 for Y and 128<<8 for Cb and Cr.
+		fillPattern(data[:luma], 0, 16)
+		fillPattern(data[luma:2*luma], 0, 128)
+		fillPattern(data[2*luma:], 255)
+	case FourCCTypeV210:
+		//Y at 64 and Cb and Cr at 512 in the four words of six pixels: Cb Y Cr, Y Cb Y, Cr Y Cb, Y Cr Y.
+		for y := 0; y < height; y++ {
+			fillPattern(data[y*stride:(y+1)*stride],
+				0x00, 0x02, 0x01, 0x20,
+				0x40, 0x00, 0x08, 0x04,
+				0x00, 0x02, 0x01, 0x20,
+				0x40, 0x00, 0x08, 0x04)
+		}
+	}
+	return data
+}
+
+//Fills b with repetitions of pattern, the last one cut off if it does not fit.
+func fillPattern(b []byte, pattern ...byte) {
+	for i := range b {
+		b[i] = pattern[i%len(pattern)]
+	}
+}
+
+//ProcessAudio applies the audio mute to af in-place. When the state changed since the last frame, the gain
+//ramps linearly across af from its previous to its new value.
+func (m *MuteController) ProcessAudio(af *AudioFrameV2) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target := float32(1)
+	if m.audioMuted {
+		target = 0
+	}
+
+	from := m.audioGain
+	m.audioGain = target
+	if from == 1 && target == 1 || af.Data == nil || af.NumSamples <= 0 {
+		return
+	}
+
+	n := int(af.NumSamples)
+	for ch := 0; ch < int(af.NumChannels); ch++ {
+		samples := af.ReadChannel(ch)
+		for i := range samples {
+			gain := target
+			if from != target {
+				gain = from + (target-from)*float32(i+1)/float32(n)
+			}
+			samples[i] *= gain
+		}
+	}
+}
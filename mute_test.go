@@ -0,0 +1,143 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestMuteVideo(t *testing.T) {
+	m := NewMuteController()
+
+	var events []MuteEvent
+	m.OnChange = func(ev MuteEvent) { events = append(events, ev) }
+
+	vf, _ := newTestVideoFrame(FourCCTypeUYVY, 4, 2, 2, func(x, y int) byte { return 200 })
+	vf.Timecode = 1234
+
+	if out := m.ProcessVideo(vf); out != vf {
+		t.Error("Expected the frame to pass through while unmuted.")
+	}
+
+	m.SetVideoMuted(true)
+	m.SetVideoMuted(true)
+	out := m.ProcessVideo(vf)
+	if out.Format() != vf.Format() || out.Timecode != vf.Timecode || out.LineStride != vf.LineStride {
+		t.Errorf("Black frame %+v does not keep the format and timing of %+v.", out, vf)
+	}
+	for i, v := range out.data() {
+		if expected := []byte{128, 16}[i%2]; v != expected {
+			t.Fatalf("Byte %d of the black frame is %d, expected %d.", i, v, expected)
+		}
+	}
+
+	if len(events) != 1 || events[0] != (MuteEvent{VideoMuted: true}) {
+		t.Errorf("Expected a single mute event but got %v.", events)
+	}
+}
+
+func TestMuteAudioRamp(t *testing.T) {
+	m := NewMuteController()
+
+	data := make([]float32, 8)
+	af := NewAudioFrameV2()
+	af.NumChannels = 2
+	af.NumSamples = 4
+	af.ChannelStride = 16
+	af.Data = &data[0]
+
+	fill := func() {
+		for i := range data {
+			data[i] = 1
+		}
+	}
+
+	check := func(expected []float32) {
+		t.Helper()
+		for i, v := range data {
+			if v != expected[i%4] {
+				t.Errorf("Sample %d is %v, expected %v.", i, v, expected[i%4])
+			}
+		}
+	}
+
+	fill()
+	m.SetAudioMuted(true)
+	m.ProcessAudio(af)
+	check([]float32{0.75, 0.5, 0.25, 0})
+
+	fill()
+	m.ProcessAudio(af)
+	check([]float32{0, 0, 0, 0})
+
+	fill()
+	m.SetAudioMuted(false)
+	m.ProcessAudio(af)
+	check([]float32{0.25, 0.5, 0.75, 1})
+
+	fill()
+	m.ProcessAudio(af)
+	check([]float32{1, 1, 1, 1})
+}
+
+func TestBlackVideoData(t *testing.T) {
+	repeat := func(pattern ...byte) func(int) byte {
+		return func(i int) byte { return pattern[i%len(pattern)] }
+	}
+	planes := func(size int, first, rest func(int) byte) func(int) byte {
+		return func(i int) byte {
+			if i < size {
+				return first(i)
+			}
+			return rest(i - size)
+		}
+	}
+
+	//Frames of 4x2 pixels.
+	tests := []struct {
+		fourCC [4]byte
+		stride int32
+		size   int
+		want   func(i int) byte
+	}{
+		{FourCCTypeBGRA, 16, 32, repeat(0, 0, 0, 255)},
+		{FourCCTypeBGRX, 16, 32, repeat(0, 0, 0, 255)},
+		{FourCCTypeRGBA, 16, 32, repeat(0, 0, 0, 255)},
+		{FourCCTypeRGBX, 16, 32, repeat(0, 0, 0, 255)},
+		{FourCCTypeUYVY, 8, 16, repeat(128, 16)},
+		{FourCCTypeUYVA, 8, 24, planes(16, repeat(128, 16), repeat(255))},
+		{FourCCTypeNV12, 4, 12, planes(8, repeat(16), repeat(128))},
+		{FourCCTypeI420, 4, 12, planes(8, repeat(16), repeat(128))},
+		{FourCCTypeYV12, 4, 12, planes(8, repeat(16), repeat(128))},
+		{FourCCTypeP216, 8, 32, planes(16, repeat(0, 16), repeat(0, 128))},
+		{FourCCTypePA16, 8, 48, planes(16, repeat(0, 16), planes(16, repeat(0, 128), repeat(255)))},
+	}
+	for _, test := range tests {
+		data := blackVideoData(&VideoFrameV2{FourCC: test.fourCC, Xres: 4, Yres: 2, LineStride: test.stride})
+		if len(data) != test.size {
+			t.Errorf("Expected %d bytes of black %s but got %d.", test.size, test.fourCC[:], len(data))
+			continue
+		}
+		for i, v := range data {
+			if want := test.want(i); v != want {
+				t.Errorf("Byte %d of black %s is %d, expected %d.", i, test.fourCC[:], v, want)
+				break
+			}
+		}
+	}
+
+	//Six pixels of V210 take four words, the rest of the 128 byte line is padding.
+	data := blackVideoData(&VideoFrameV2{FourCC: FourCCTypeV210, Xres: 6, Yres: 2, LineStride: 128})
+	if len(data) != 256 {
+		t.Fatalf("Expected 256 bytes of black V210 but got %d.", len(data))
+	}
+	components := []uint32{512, 64, 512, 64, 512, 64}
+	for w := 0; w < len(data)/4; w++ {
+		word := uint32(data[w*4]) | uint32(data[w*4+1])<<8 | uint32(data[w*4+2])<<16 | uint32(data[w*4+3])<<24
+		for c := 0; c < 3; c++ {
+			if v, want := word>>(10*c)&0x3ff, components[(w*3+c)%6]; v != want {
+				t.Fatalf("Component %d of word %d of black V210 is %d, expected %d.", c, w, v, want)
+			}
+		}
+	}
+}
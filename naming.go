@@ -0,0 +1,103 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var namePlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+//NameTemplate builds sender names from a template such as "{site}-{room}-CAM{env:CAM_NO}". The placeholders are
+//{hostname}, {env:NAME} for environment variables and any key of Vars.
+type NameTemplate struct {
+	Template string
+	Vars     map[string]string
+}
+
+//Expand replaces the placeholders of the template. Unknown placeholders and unset environment variables are an error.
+func (t NameTemplate) Expand() (string, error) {
+	var err error
+	name := namePlaceholder.ReplaceAllStringFunc(t.Template, func(m string) string {
+		key := m[1 : len(m)-1]
+
+		switch {
+		case key == "hostname":
+			host, e := os.Hostname()
+			if e != nil && err == nil {
+				err = e
+			}
+			return host
+
+		case strings.HasPrefix(key, "env:"):
+			v, ok := os.LookupEnv(key[len("env:"):])
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %s is not set", key[len("env:"):])
+			}
+			return v
+		}
+
+		v, ok := t.Vars[key]
+		if !ok && err == nil {
+			err = fmt.Errorf("unknown placeholder %s", m)
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+//Resolve expands the template and makes the result unique among taken, see UniqueSenderName.
+func (t NameTemplate) Resolve(taken []string) (string, error) {
+	name, err := t.Expand()
+	if err != nil {
+		return "", err
+	}
+	return UniqueSenderName(name, taken), nil
+}
+
+//senderName returns the sender part of a full NDI source name, "MACHINE (sender)".
+func senderName(sourceName string) string {
+	if i := strings.Index(sourceName, " ("); i >= 0 && strings.HasSuffix(sourceName, ")") {
+		return sourceName[i+2 : len(sourceName)-1]
+	}
+	return sourceName
+}
+
+//UniqueSenderName returns name, or name with the lowest numeric suffix ("-2", "-3", ...) that does not collide
+//with any of taken. taken may hold full NDI source names as reported by discovery, and is compared without
+//regard to case or to the machine the source runs on.
+func UniqueSenderName(name string, taken []string) string {
+	used := make(map[string]struct{}, len(taken))
+	for _, n := range taken {
+		used[strings.ToLower(senderName(n))] = struct{}{}
+	}
+
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := used[strings.ToLower(candidate)]; !ok {
+			return candidate
+		}
+		candidate = name + "-" + strconv.Itoa(i)
+	}
+}
+
+//DiscoveredSourceNames waits up to timeoutInMs for sources to show up and returns the names of the ones found.
+func DiscoveredSourceNames(finder *FindInstance, timeoutInMs uint32) []string {
+	finder.WaitForSources(timeoutInMs)
+
+	sources := finder.GetCurrentSources()
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = s.Name()
+	}
+	return names
+}
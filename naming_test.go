@@ -0,0 +1,35 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNameTemplate(t *testing.T) {
+	os.Setenv("NDI_GO_TEST_FUNCTION", "CAM")
+	defer os.Unsetenv("NDI_GO_TEST_FUNCTION")
+
+	tmpl := NameTemplate{
+		Template: "{site}-{room}-{env:NDI_GO_TEST_FUNCTION}",
+		Vars:     map[string]string{"site": "TYO", "room": "A1"},
+	}
+
+	name, err := tmpl.Resolve([]string{"STUDIO-PC (TYO-A1-CAM)", "OTHER-PC (tyo-a1-cam-2)", "TYO-A1-CAM-4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "TYO-A1-CAM-3" {
+		t.Errorf("Expected TYO-A1-CAM-3 but got %s.", name)
+	}
+
+	if _, err := (NameTemplate{Template: "{site}-{floor}", Vars: tmpl.Vars}).Expand(); err == nil {
+		t.Error("Expected an error for an unknown placeholder.")
+	}
+	if _, err := (NameTemplate{Template: "{env:NDI_GO_TEST_UNSET}"}).Expand(); err == nil {
+		t.Error("Expected an error for an unset environment variable.")
+	}
+}
@@ -22,6 +22,7 @@ var (
 	funcPtrs         *ndiLIBv5
 )
 
+//Matches NDIlib_tally_t, two C bools of one byte each.
 type Tally struct {
 	OnProgram, OnPreview bool
 }
@@ -103,6 +104,25 @@ func (p *ObjectPool) NewFindCreateSettings(showLocalSources bool, groups, ips st
 	return o
 }
 
+type RoutingCreateSettings struct {
+	ndiName, groups *byte
+}
+
+func (p *ObjectPool) NewRoutingCreateSettings(name, groups string) *RoutingCreateSettings {
+	o := &RoutingCreateSettings{cString(name), cString(groups)}
+	p.Register(o)
+	return o
+}
+
+//Allocates a metadata frame holding the given XML. The frame and its string stay alive as long as the pool does,
+//so the SDK may keep reading them after the call that was given the frame returns.
+func (p *ObjectPool) NewMetadataFrame(data string) *MetadataFrame {
+	o := NewMetadataFrame()
+	o.Data = cString(data)
+	p.Register(o)
+	return o
+}
+
 func LoadAndInitialize(path string) error {
 	if ndiSharedLibrary != 0 {
 		return alreadyLoadedErr
@@ -5,9 +5,13 @@
 package ndi
 
 import (
+	"math"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 const ndiLibName = "Processing.NDI.Lib.x64.dll"
@@ -48,3 +52,325 @@ func TestFrame(t *testing.T) {
 	inst.SendVideoV2(frame)
 	inst.Destroy()
 }
+
+func TestSendAudio(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	settings := pool.NewSendCreateSettings("ndi-go audio test", "", false, false)
+	inst := NewSendInstance(settings)
+	defer inst.Destroy()
+
+	const (
+		sampleRate = 48000
+		numSamples = 1600
+	)
+
+	//A 440Hz tone on the left and 880Hz on the right.
+	var n int
+	for i := 0; i < 3000; i++ {
+		left, right := make([]float32, numSamples), make([]float32, numSamples)
+		for j := range left {
+			phase := 2 * math.Pi * float64(n) / sampleRate
+			left[j] = float32(0.5 * math.Sin(440*phase))
+			right[j] = float32(0.5 * math.Sin(880*phase))
+			n++
+		}
+
+		if err := inst.SendAudioV2(newPlanarAudioFrame([][]float32{left, right}, sampleRate)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	frame := NewAudioFrameV2()
+	frame.NumChannels, frame.NumSamples = 2, numSamples
+	if err := inst.SendAudioV2(frame); err != invalidAudioFrameErr {
+		t.Errorf("Expected %v but got %v.", invalidAudioFrameErr, err)
+	}
+}
+
+func TestSendAudioV3(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	inst := NewSendInstance(pool.NewSendCreateSettings("ndi-go audio v3 test", "", false, false))
+	defer inst.Destroy()
+
+	silence := make([]float32, 2*1600)
+	frame := NewAudioFrameV3()
+	frame.NumSamples = 1600
+	frame.ChannelStride = 1600 * 4
+	frame.Data = (*byte)(unsafe.Pointer(&silence[0]))
+	if err := inst.SendAudioV3(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	frame.ChannelStride = 4
+	if err := inst.SendAudioV3(frame); err != invalidAudioFrameErr {
+		t.Errorf("Expected %v but got %v.", invalidAudioFrameErr, err)
+	}
+}
+
+func TestSendAudioInterleaved(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	inst := NewSendInstance(pool.NewSendCreateSettings("ndi-go interleaved audio test", "", false, false))
+	defer inst.Destroy()
+
+	s16 := make([]int16, 2*1600)
+	af16 := NewAudioFrameInterleaved16s()
+	af16.NumSamples, af16.Data = 1600, &s16[0]
+	if err := inst.SendAudioInterleaved16s(af16); err != nil {
+		t.Error(err)
+	}
+
+	s32 := make([]int32, 2*1600)
+	af32 := NewAudioFrameInterleaved32s()
+	af32.NumSamples, af32.Data = 1600, &s32[0]
+	if err := inst.SendAudioInterleaved32s(af32); err != nil {
+		t.Error(err)
+	}
+
+	f32 := make([]float32, 2*1600)
+	af32f := NewAudioFrameInterleaved32f()
+	af32f.NumSamples, af32f.Data = 1600, &f32[0]
+	if err := inst.SendAudioInterleaved32f(af32f); err != nil {
+		t.Error(err)
+	}
+
+	//Samples without data are refused.
+	if err := inst.SendAudioInterleaved16s(&AudioFrameInterleaved16s{NumChannels: 2, NumSamples: 1600}); err != invalidAudioFrameErr {
+		t.Errorf("Expected %v but got %v.", invalidAudioFrameErr, err)
+	}
+}
+
+func TestAudioInterleavedConversion(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	src := newPlanarAudioFrame([][]float32{{0, 0.5, -0.5}, {1, -1, 0.25}}, 48000)
+	src.Timecode = 1234
+
+	s16 := make([]int16, 2*3)
+	i16 := NewAudioFrameInterleaved16s()
+	i16.Data = &s16[0]
+	if err := AudioToInterleaved16sV2(src, i16); err != nil {
+		t.Fatal(err)
+	}
+	if i16.NumChannels != 2 || i16.NumSamples != 3 || i16.Timecode != 1234 {
+		t.Errorf("Expected the format of the source but got %+v.", i16)
+	}
+
+	data := make([]float32, 2*3)
+	back := NewAudioFrameV2()
+	back.Data, back.ChannelStride = &data[0], 3*4
+	if err := AudioFromInterleaved16sV2(i16, back); err != nil {
+		t.Fatal(err)
+	}
+	for ch := 0; ch < 2; ch++ {
+		want, got := src.ReadChannel(ch), back.ReadChannel(ch)
+		for i := range want {
+			if math.Abs(float64(want[i]-got[i])) > 1e-3 {
+				t.Errorf("Expected %v but got %v.", want, got)
+				break
+			}
+		}
+	}
+
+	if err := AudioToInterleaved32fV2(src, NewAudioFrameInterleaved32f()); err != invalidAudioFrameErr {
+		t.Errorf("Expected %v without destination data but got %v.", invalidAudioFrameErr, err)
+	}
+}
+
+func TestFramesyncAudioQueueDepth(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	sender := NewSendInstance(pool.NewSendCreateSettings("ndi-go framesync test", "", false, false))
+	defer sender.Destroy()
+
+	finder := NewFindInstance(NewFindSettings())
+	defer finder.Destroy()
+
+	var source *Source
+	for i := 0; i < 10 && source == nil; i++ {
+		finder.WaitForSources(1000)
+		for _, s := range finder.GetCurrentSources() {
+			if strings.HasSuffix(s.Name(), "(ndi-go framesync test)") {
+				source = s
+			}
+		}
+	}
+	if source == nil {
+		t.Fatal("test sender was not found")
+	}
+
+	settings := NewRecvCreateSettings()
+	settings.SourceToConnectTo = *source
+	recv := NewRecvInstance(settings)
+	defer recv.Destroy()
+
+	fs := NewFramesyncInstance(recv)
+	defer fs.Destroy()
+
+	//Audio is sent but never captured, so it has to pile up.
+	silence := [][]float32{make([]float32, 1600), make([]float32, 1600)}
+	depth := fs.AudioQueueDepth()
+	for i := 0; i < 150 && fs.AudioQueueDepth() <= depth; i++ {
+		if err := sender.SendAudioV2(newPlanarAudioFrame(silence, 48000)); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(33 * time.Millisecond)
+	}
+	if d := fs.AudioQueueDepth(); d <= depth {
+		t.Errorf("Expected the audio queue to grow beyond %d samples but it is %d.", depth, d)
+	}
+
+	af := fs.CaptureAudioV2(48000, 2, 1600)
+	if af.NumSamples != 1600 || af.FourCC != FourCCAudioTypeFLTP {
+		t.Errorf("Expected 1600 samples of FLTP but got %d of %x.", af.NumSamples, af.FourCC)
+	}
+	fs.FreeAudioV2(af)
+
+	//Drain in chunks of 480 samples, 10ms at 48kHz, until less than a chunk is left.
+	depth = fs.AudioQueueDepth()
+	chunks := fs.DrainAudioV2(48000, 2, 480, func(af *AudioFrameV3) {
+		if af.NumSamples != 480 {
+			t.Errorf("Expected chunks of 480 samples but got %d.", af.NumSamples)
+		}
+	})
+	if chunks < depth/480 || fs.AudioQueueDepth() >= 480 {
+		t.Errorf("Expected at least %d chunks and less than 480 samples left but got %d and %d.", depth/480, chunks, fs.AudioQueueDepth())
+	}
+}
+
+func TestSendMetadata(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	settings := pool.NewSendCreateSettings("ndi-go metadata test", "", false, false)
+	inst := NewSendInstance(settings)
+	defer inst.Destroy()
+
+	data := []byte("<camera id=\"1\"/>\x00")
+	mf := NewMetadataFrame()
+	mf.Data = &data[0]
+	inst.SendMetadata(mf)
+
+	//Nothing is connected, so the capture times out.
+	var back MetadataFrame
+	if ft, err := inst.Capture(&back, 10); ft != FrameTypeNone || err != nil {
+		t.Errorf("Expected a timeout without an error but got %v and %v.", ft, err)
+	}
+	inst.FreeMetadata(&back)
+
+	if mf, err := inst.CaptureMetadata(10); mf != nil || err != nil {
+		t.Errorf("Expected no frame and no error but got %v and %v.", mf, err)
+	}
+}
+
+func TestConnectionMetadata(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	send := NewSendInstance(pool.NewSendCreateSettings("ndi-go connection metadata test", "", false, false))
+	defer send.Destroy()
+	recv := NewRecvInstanceV2(NewRecvCreateSettings())
+	defer recv.Destroy()
+
+	//Clearing before anything was added is harmless.
+	send.ClearConnectionMetadata()
+	recv.ClearConnectionMetadata()
+
+	send.AddConnectionMetadata(pool.NewMetadataFrame(`<ndi_product long_name="ndi-go test"/>`))
+	recv.AddConnectionMetadata(pool.NewMetadataFrame(`<ndi_product long_name="ndi-go test"/>`))
+	send.ClearConnectionMetadata()
+	recv.ClearConnectionMetadata()
+}
+
+func TestSetFailover(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	inst := NewSendInstance(pool.NewSendCreateSettings("ndi-go failover test", "", false, false))
+	defer inst.Destroy()
+
+	src := NewSource("BACKUP (ndi-go)", "")
+	inst.SetFailover(&src)
+	inst.SetFailover(nil)
+
+	inst.SetFailover(&src)
+	inst.ClearFailover()
+}
+
+func TestSourceName(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	pool := NewObjectPool()
+	inst := NewSendInstance(pool.NewSendCreateSettings("ndi-go source name test", "", false, false))
+	defer inst.Destroy()
+
+	source, err := inst.SourceName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(source.Name(), "(ndi-go source name test)") {
+		t.Errorf("Expected the machine name to be prepended but got %q.", source.Name())
+	}
+}
+
+func TestV210P216Conversion(t *testing.T) {
+	doInit(t)
+	defer DestroyAndUnload()
+
+	newFrame := func(fourCC [4]byte, stride int32, size int) *VideoFrameV2 {
+		data := make([]byte, size)
+		vf := NewVideoFrameV2()
+		vf.FourCC, vf.Xres, vf.Yres, vf.LineStride = fourCC, 96, 2, stride
+		vf.Data = &data[0]
+		return vf
+	}
+
+	//Mid grey, Y and CbCr at 512 in 10-bit.
+	v210 := newFrame(FourCCTypeV210, 256, 256*2)
+	for i, data := 0, v210.ReadData(); i < len(data); i += 4 {
+		word := uint32(512) | 512<<10 | 512<<20
+		data[i], data[i+1], data[i+2], data[i+3] = byte(word), byte(word>>8), byte(word>>16), byte(word>>24)
+	}
+	p216 := newFrame(FourCCTypeP216, 96*2, 96*2*2*2)
+	if err := V210ToP216(v210, p216); err != nil {
+		t.Fatal(err)
+	}
+	if y := uint16(p216.ReadData()[0]) | uint16(p216.ReadData()[1])<<8; y>>6 != 512 {
+		t.Errorf("Expected the first Y to be 512 in 10 bits but got %d.", y>>6)
+	}
+
+	back := newFrame(FourCCTypeV210, 256, 256*2)
+	if err := P216ToV210(p216, back); err != nil {
+		t.Fatal(err)
+	}
+	if string(back.ReadData()[:128]) != string(v210.ReadData()[:128]) {
+		t.Error("Expected the round trip to restore the V210 data.")
+	}
+
+	p216.Xres = 48
+	if err := V210ToP216(v210, p216); err != frameMismatchErr {
+		t.Errorf("Expected %v but got %v.", frameMismatchErr, err)
+	}
+}
+
+func TestTallyLayout(t *testing.T) {
+	var tally Tally
+	if size, offset := unsafe.Sizeof(tally), unsafe.Offsetof(tally.OnPreview); size != 2 || offset != 1 {
+		t.Errorf("Expected Tally to be 2 bytes with OnPreview at 1 but got %d bytes with OnPreview at %d.", size, offset)
+	}
+}
@@ -0,0 +1,130 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+//Package nditest provides helpers for testing code built on the ndi package.
+package nditest
+
+import (
+	"sync"
+	"time"
+)
+
+//Timer and Ticker are the timers a clock hands out. They are aliases of unnamed interfaces, so FakeClock
+//satisfies the clock interface of the ndi package without either package naming the other.
+type (
+	Timer = interface {
+		C() <-chan time.Time
+		Stop() bool
+	}
+
+	Ticker = interface {
+		C() <-chan time.Time
+		Stop()
+	}
+)
+
+//FakeClock only moves when it is advanced. Timers and tickers fire during Advance and Sleep advances the clock
+//instead of blocking. It is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+	stopped  bool
+}
+
+//NewFakeClock returns a clock that starts at the UNIX epoch.
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) add(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &fakeWaiter{c: make(chan time.Time, 1), deadline: c.now.Add(d), period: period}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer { return fakeTimer{c, c.add(d, 0)} }
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker { return fakeTicker{c, c.add(d, d)} }
+
+func (c *FakeClock) Sleep(d time.Duration) { c.Advance(d) }
+
+//Advance moves the clock forward, firing every timer and ticker that becomes due. Like real tickers, a
+//ticker whose channel is full drops ticks.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, w := range c.waiters {
+		for !w.stopped && !w.deadline.After(c.now) {
+			select {
+			case w.c <- w.deadline:
+			default:
+			}
+
+			if w.period == 0 {
+				w.stopped = true
+			} else {
+				w.deadline = w.deadline.Add(w.period)
+			}
+		}
+	}
+}
+
+//Pending returns the number of timers and tickers that have not fired or been stopped yet.
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int
+	for _, w := range c.waiters {
+		if !w.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *FakeClock) stop(w *fakeWaiter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := !w.stopped
+	w.stopped = true
+	return active
+}
+
+type fakeTimer struct {
+	c *FakeClock
+	w *fakeWaiter
+}
+
+func (t fakeTimer) C() <-chan time.Time { return t.w.c }
+
+func (t fakeTimer) Stop() bool { return t.c.stop(t.w) }
+
+type fakeTicker struct {
+	c *FakeClock
+	w *fakeWaiter
+}
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.c }
+
+func (t fakeTicker) Stop() { t.c.stop(t.w) }
@@ -0,0 +1,38 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package nditest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	c := NewFakeClock()
+	start := c.Now()
+
+	tm := c.NewTimer(time.Second)
+	tk := c.NewTicker(400 * time.Millisecond)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-tm.C():
+		t.Error("Timer fired early.")
+	case at := <-tk.C():
+		if at != start.Add(400*time.Millisecond) {
+			t.Errorf("Unexpected tick time %v.", at.Sub(start))
+		}
+	}
+
+	c.Advance(500 * time.Millisecond)
+	if at := <-tm.C(); at != start.Add(time.Second) {
+		t.Errorf("Unexpected timer time %v.", at.Sub(start))
+	}
+
+	tk.Stop()
+	if n := c.Pending(); n != 0 {
+		t.Errorf("Expected no pending waiters but got %d.", n)
+	}
+}
@@ -0,0 +1,163 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	noPeerVersionErr   = errors.New("the peer did not announce its protocol versions")
+	noCommonVersionErr = errors.New("no common protocol version")
+	invalidVersionErr  = errors.New("invalid protocol version")
+)
+
+const (
+	//How long NegotiateVersion waits for the announcement of the peer.
+	negotiateTimeout = 5 * time.Second
+
+	//How long each capture of NegotiateVersion waits for metadata, in milliseconds.
+	negotiatePollTimeout = 100
+)
+
+//NDIProtocolVersion is a version of the NDI API, as far as the runtime of one side supports it.
+type NDIProtocolVersion struct {
+	Major, Minor int
+}
+
+func (v NDIProtocolVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func (v NDIProtocolVersion) less(o NDIProtocolVersion) bool {
+	return v.Major < o.Major || v.Major == o.Major && v.Minor < o.Minor
+}
+
+func parseProtocolVersion(s string) (NDIProtocolVersion, error) {
+	var v NDIProtocolVersion
+	if n, err := fmt.Sscanf(s, "%d.%d", &v.Major, &v.Minor); n != 2 || err != nil || v.Major < 0 || v.Minor < 0 {
+		return NDIProtocolVersion{}, invalidVersionErr
+	}
+	return v, nil
+}
+
+//The connection metadata both sides announce during NegotiateVersion.
+type protocolVersionMetadata struct {
+	XMLName  xml.Name `xml:"ndi_go_protocol"`
+	Versions string   `xml:"versions,attr"`
+}
+
+func marshalProtocolVersions(versions []NDIProtocolVersion) string {
+	s := make([]string, len(versions))
+	for i, v := range versions {
+		s[i] = v.String()
+	}
+	b, _ := xml.Marshal(protocolVersionMetadata{Versions: strings.Join(s, " ")})
+	return string(b)
+}
+
+//Returns the versions announced in metadata, or false if it is no version announcement.
+func parseProtocolVersions(metadata string) ([]NDIProtocolVersion, bool) {
+	var md protocolVersionMetadata
+	if err := xml.Unmarshal([]byte(metadata), &md); err != nil {
+		return nil, false
+	}
+
+	var versions []NDIProtocolVersion
+	for _, s := range strings.Fields(md.Versions) {
+		if v, err := parseProtocolVersion(s); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions, true
+}
+
+func highestCommonVersion(local, remote []NDIProtocolVersion) (NDIProtocolVersion, error) {
+	common := make([]NDIProtocolVersion, 0, len(local))
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r {
+				common = append(common, l)
+				break
+			}
+		}
+	}
+	if len(common) == 0 {
+		return NDIProtocolVersion{}, noCommonVersionErr
+	}
+
+	sort.Slice(common, func(i, j int) bool { return common[j].less(common[i]) })
+	return common[0], nil
+}
+
+//The API versions the loaded runtime implements, judged by the calls each version added.
+func localProtocolVersions() []NDIProtocolVersion {
+	versions := []NDIProtocolVersion{{3, 0}}
+	for _, v := range []struct {
+		fn      uintptr
+		version NDIProtocolVersion
+	}{
+		{funcPtrs.NDIlibSourceTv38, NDIProtocolVersion{3, 8}},
+		{funcPtrs.NDIlibSendSendAudioV3, NDIProtocolVersion{4, 0}},
+		{funcPtrs.NDIlibFrameTypeE, NDIProtocolVersion{4, 1}},
+		{funcPtrs.NDIlibRecvPtzExposureManualV2, NDIProtocolVersion{4, 5}},
+	} {
+		if v.fn != 0 {
+			versions = append(versions, v.version)
+		}
+	}
+	return versions
+}
+
+//NegotiateVersion announces the API versions of the loaded runtime in the connection metadata of sender and
+//receiver, waits up to five seconds for the source of receiver to announce its versions and returns the highest
+//version both support. This is meant for debugging interoperability, the SDK negotiates the wire protocol on its
+//own.
+//
+//Connection metadata is only exchanged when a connection is made, so call it before receiver connects or
+//reconnect it afterwards. Peers that are not built with this package do not announce their versions, which
+//results in an error. Frames other than metadata that receiver gets in the meantime are dropped. The connection
+//metadata restrictions of SendInstance.AddConnectionMetadata apply.
+func NegotiateVersion(sender *SendInstance, receiver *RecvInstance) (NDIProtocolVersion, error) {
+	local := localProtocolVersions()
+	announcement := marshalProtocolVersions(local)
+
+	if sender != nil {
+		if err := sender.AddConnectionMetadataXML(announcement); err != nil {
+			return NDIProtocolVersion{}, err
+		}
+	}
+	if receiver == nil {
+		return NDIProtocolVersion{}, noPeerVersionErr
+	}
+
+	data := []byte(announcement + "\x00")
+	mf := NewMetadataFrame()
+	mf.Data = &data[0]
+	receiver.AddConnectionMetadata(mf)
+	runtime.KeepAlive(data)
+
+	deadline := sysClock.Now().Add(negotiateTimeout)
+	for sysClock.Now().Before(deadline) {
+		var md MetadataFrame
+		switch receiver.CaptureV2(nil, nil, &md, negotiatePollTimeout) {
+		case FrameTypeMetadata:
+			remote, ok := parseProtocolVersions(md.ReadString())
+			receiver.FreeMetadataV2(&md)
+			if ok {
+				return highestCommonVersion(local, remote)
+			}
+		case FrameTypeError:
+			return NDIProtocolVersion{}, connectionLostErr
+		}
+	}
+	return NDIProtocolVersion{}, noPeerVersionErr
+}
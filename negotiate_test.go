@@ -0,0 +1,51 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtocolVersionMetadata(t *testing.T) {
+	versions := []NDIProtocolVersion{{3, 0}, {4, 1}, {4, 5}}
+	md := marshalProtocolVersions(versions)
+	if md != `<ndi_go_protocol versions="3.0 4.1 4.5"></ndi_go_protocol>` {
+		t.Errorf("Unexpected metadata %s.", md)
+	}
+
+	if got, ok := parseProtocolVersions(md); !ok || !reflect.DeepEqual(got, versions) {
+		t.Errorf("Expected %v but got %v.", versions, got)
+	}
+
+	//Invalid entries are skipped.
+	if got, ok := parseProtocolVersions(`<ndi_go_protocol versions="5 x.1 5.0"/>`); !ok || !reflect.DeepEqual(got, []NDIProtocolVersion{{5, 0}}) {
+		t.Errorf("Expected only 5.0 but got %v.", got)
+	}
+
+	//Other metadata is no announcement.
+	if _, ok := parseProtocolVersions(`<ndi_tally on_program="true"/>`); ok {
+		t.Error("Expected tally metadata not to be a version announcement.")
+	}
+}
+
+func TestHighestCommonVersion(t *testing.T) {
+	local := []NDIProtocolVersion{{3, 0}, {3, 8}, {4, 0}, {4, 5}}
+	tests := []struct {
+		remote []NDIProtocolVersion
+		want   NDIProtocolVersion
+		err    error
+	}{
+		{[]NDIProtocolVersion{{4, 5}, {3, 0}, {4, 0}}, NDIProtocolVersion{4, 5}, nil},
+		{[]NDIProtocolVersion{{3, 8}, {5, 0}}, NDIProtocolVersion{3, 8}, nil},
+		{[]NDIProtocolVersion{{5, 0}}, NDIProtocolVersion{}, noCommonVersionErr},
+		{nil, NDIProtocolVersion{}, noCommonVersionErr},
+	}
+	for _, test := range tests {
+		if got, err := highestCommonVersion(local, test.remote); got != test.want || err != test.err {
+			t.Errorf("Expected %v (%v) for %v but got %v (%v).", test.want, test.err, test.remote, got, err)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	missingNameErr   = errors.New("name is required")
+	nameAndTemplErr  = errors.New("name and name template are mutually exclusive")
+	duplicateNameErr = errors.New("name is already used by another component")
+	missingSourceErr = errors.New("source is required")
+	unknownLinkErr   = errors.New("link refers to an unknown receiver or sender")
+	duplicateLinkErr = errors.New("receiver or sender is already used by another link")
+	bandwidthErr     = errors.New("invalid bandwidth")
+	colorFormatErr   = errors.New("invalid color format")
+	createSenderErr  = errors.New("unable to create sender")
+	createRecvErr    = errors.New("unable to create receiver")
+	createRoutingErr = errors.New("unable to create routing source")
+)
+
+//PipelineConfig declares a set of senders, receivers and routing sources that BuildPipeline materializes in one go.
+//The struct tags make it usable with encoding/json as well as the common YAML packages.
+type PipelineConfig struct {
+	Senders   []SenderConfig   `json:"senders,omitempty" yaml:"senders,omitempty"`
+	Receivers []ReceiverConfig `json:"receivers,omitempty" yaml:"receivers,omitempty"`
+	Routing   []RoutingConfig  `json:"routing,omitempty" yaml:"routing,omitempty"`
+	Links     []LinkConfig     `json:"links,omitempty" yaml:"links,omitempty"`
+
+	//Values for the placeholders of sender name templates.
+	NameVars map[string]string `json:"name_vars,omitempty" yaml:"name_vars,omitempty"`
+
+	//When set, templated sender names are checked against the sources currently on the network and get a
+	//numeric suffix on collision. Leave it unset for offline starts.
+	CheckNameCollisions bool `json:"check_name_collisions,omitempty" yaml:"check_name_collisions,omitempty"`
+}
+
+//How long BuildPipeline waits for discovery before checking sender names for collisions.
+const nameCollisionTimeoutInMs = 1000
+
+type SenderConfig struct {
+	//The NDI name of the source, also used to refer to this component.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	//Alternative to Name, see NameTemplate. The template refers to this component in errors.
+	NameTemplate string `json:"name_template,omitempty" yaml:"name_template,omitempty"`
+
+	Groups     string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ClockVideo bool   `json:"clock_video,omitempty" yaml:"clock_video,omitempty"`
+	ClockAudio bool   `json:"clock_audio,omitempty" yaml:"clock_audio,omitempty"`
+}
+
+type ReceiverConfig struct {
+	//The name used to refer to this component.
+	Name string `json:"name" yaml:"name"`
+
+	//The NDI name of the source to connect to and optionally its address.
+	Source        string `json:"source" yaml:"source"`
+	SourceAddress string `json:"source_address,omitempty" yaml:"source_address,omitempty"`
+
+	ColorFormat      RecvColorFormat `json:"color_format,omitempty" yaml:"color_format,omitempty"`
+	Bandwidth        RecvBandwidth   `json:"bandwidth" yaml:"bandwidth"`
+	AllowVideoFields bool            `json:"allow_video_fields,omitempty" yaml:"allow_video_fields,omitempty"`
+}
+
+//RoutingConfig declares a routing source, which sends the receivers connecting to it on to another source.
+type RoutingConfig struct {
+	//The NDI name of the routing source, also used to refer to this component.
+	Name   string `json:"name" yaml:"name"`
+	Groups string `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	//The NDI name of the source to route to initially and optionally its address. Without a source, receivers
+	//wait until the route is changed.
+	Source        string `json:"source,omitempty" yaml:"source,omitempty"`
+	SourceAddress string `json:"source_address,omitempty" yaml:"source_address,omitempty"`
+}
+
+//LinkConfig forwards the video a receiver captures to a sender once the pipeline is started.
+type LinkConfig struct {
+	//The names of the receiver and the sender. Templated senders are referred to by their template.
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+//PipelineConfigError points at the component of a PipelineConfig that is invalid or could not be built.
+type PipelineConfigError struct {
+	Kind, Name string
+	Err        error
+}
+
+func (e *PipelineConfigError) Error() string {
+	return fmt.Sprintf("%s %q: %v", e.Kind, e.Name, e.Err)
+}
+
+func (e *PipelineConfigError) Unwrap() error {
+	return e.Err
+}
+
+//Validate checks the configuration without touching the SDK.
+func (c *PipelineConfig) Validate() error {
+	names := make(map[string]struct{})
+	checkName := func(kind, name string) error {
+		if name == "" {
+			return &PipelineConfigError{kind, name, missingNameErr}
+		}
+		if _, ok := names[name]; ok {
+			return &PipelineConfigError{kind, name, duplicateNameErr}
+		}
+		names[name] = struct{}{}
+		return nil
+	}
+
+	for _, s := range c.Senders {
+		if s.Name != "" && s.NameTemplate != "" {
+			return &PipelineConfigError{"sender", s.Name, nameAndTemplErr}
+		}
+		if err := checkName("sender", s.Name+s.NameTemplate); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range c.Receivers {
+		if err := checkName("receiver", r.Name); err != nil {
+			return err
+		}
+		if r.Source == "" {
+			return &PipelineConfigError{"receiver", r.Name, missingSourceErr}
+		}
+
+		switch r.Bandwidth {
+		case RecvBandwidthMetadataOnly, RecvBandwidthAudioOnly, RecvBandwidthLowest, RecvBandwidthHighest:
+		default:
+			return &PipelineConfigError{"receiver", r.Name, bandwidthErr}
+		}
+
+		if !IsValidRecvColorFormat(r.ColorFormat) {
+			return &PipelineConfigError{"receiver", r.Name, colorFormatErr}
+		}
+	}
+
+	for _, r := range c.Routing {
+		if err := checkName("routing", r.Name); err != nil {
+			return err
+		}
+	}
+
+	//A receiver can only be captured from by one link, and a sender only fed by one.
+	linkedFrom, linkedTo := make(map[string]bool), make(map[string]bool)
+	for _, l := range c.Links {
+		var from, to bool
+		for _, r := range c.Receivers {
+			from = from || r.Name == l.From
+		}
+		for _, s := range c.Senders {
+			to = to || s.Name+s.NameTemplate == l.To
+		}
+		if !from || !to {
+			return &PipelineConfigError{"link", l.From + "->" + l.To, unknownLinkErr}
+		}
+
+		if linkedFrom[l.From] || linkedTo[l.To] {
+			return &PipelineConfigError{"link", l.From + "->" + l.To, duplicateLinkErr}
+		}
+		linkedFrom[l.From], linkedTo[l.To] = true, true
+	}
+	return nil
+}
+
+//Pipeline holds the instances built from a PipelineConfig, keyed by component name.
+type Pipeline struct {
+	Senders   map[string]*SendInstance
+	Receivers map[string]*RecvInstance
+	Routers   map[string]*RoutingInstance
+
+	pool  *ObjectPool
+	links []pipelineLink
+}
+
+type pipelineLink struct {
+	from *RecvInstance
+	to   *SendInstance
+}
+
+//How long a link waits for a frame before checking whether the pipeline was stopped.
+const linkCaptureTimeoutInMs = 100
+
+//BuildPipeline validates cfg and creates all of its components. If any component fails to build, the ones
+//created so far are destroyed again.
+func BuildPipeline(cfg *PipelineConfig) (*Pipeline, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Pipeline{
+		Senders:   make(map[string]*SendInstance),
+		Receivers: make(map[string]*RecvInstance),
+		Routers:   make(map[string]*RoutingInstance),
+		pool:      NewObjectPool(),
+	}
+
+	senderNames := make(map[string]string, len(cfg.Senders))
+	taken := make([]string, 0, len(cfg.Senders))
+	for _, s := range cfg.Senders {
+		if s.Name != "" {
+			taken = append(taken, s.Name)
+		}
+	}
+	if cfg.CheckNameCollisions {
+		finder := NewFindInstanceV2(p.pool.NewFindCreateSettings(true, "", ""))
+		if finder != nil {
+			taken = append(taken, DiscoveredSourceNames(finder, nameCollisionTimeoutInMs)...)
+			finder.Destroy()
+		}
+	}
+
+	for _, s := range cfg.Senders {
+		name := s.Name
+		if s.NameTemplate != "" {
+			var err error
+			if name, err = (NameTemplate{s.NameTemplate, cfg.NameVars}).Resolve(taken); err != nil {
+				p.Close()
+				return nil, &PipelineConfigError{"sender", s.NameTemplate, err}
+			}
+			taken = append(taken, name)
+		}
+
+		settings := p.pool.NewSendCreateSettings(name, s.Groups, s.ClockVideo, s.ClockAudio)
+		inst := NewSendInstance(settings)
+		if inst == nil {
+			p.Close()
+			return nil, &PipelineConfigError{"sender", name, createSenderErr}
+		}
+		p.Senders[name] = inst
+		senderNames[s.Name+s.NameTemplate] = name
+	}
+
+	for _, r := range cfg.Receivers {
+		settings := NewRecvCreateSettings()
+		settings.SourceToConnectTo = NewSource(r.Source, r.SourceAddress)
+		settings.ColorFormat = r.ColorFormat
+		settings.Bandwidth = r.Bandwidth
+		settings.AllowVideoFields = r.AllowVideoFields
+
+		inst := NewRecvInstanceV2(settings)
+		if inst == nil {
+			p.Close()
+			return nil, &PipelineConfigError{"receiver", r.Name, createRecvErr}
+		}
+		p.Receivers[r.Name] = inst
+	}
+
+	for _, r := range cfg.Routing {
+		inst := NewRoutingInstanceFromSettings(&RoutingSettings{NdiName: r.Name, Groups: r.Groups})
+		if inst == nil {
+			p.Close()
+			return nil, &PipelineConfigError{"routing", r.Name, createRoutingErr}
+		}
+		p.Routers[r.Name] = inst
+
+		if r.Source != "" {
+			source := NewSource(r.Source, r.SourceAddress)
+			if err := inst.Change(&source); err != nil {
+				p.Close()
+				return nil, &PipelineConfigError{"routing", r.Name, err}
+			}
+		}
+	}
+
+	for _, l := range cfg.Links {
+		p.links = append(p.links, pipelineLink{p.Receivers[l.From], p.Senders[senderNames[l.To]]})
+	}
+	return p, nil
+}
+
+//Start runs the links of the pipeline until ctx is done and returns once they have all stopped.
+func (p *Pipeline) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, l := range p.links {
+		wg.Add(1)
+		go func(l pipelineLink) {
+			defer wg.Done()
+			l.run(ctx)
+		}(l)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (l pipelineLink) run(ctx context.Context) {
+	var vf VideoFrameV2
+	for ctx.Err() == nil {
+		if l.from.CaptureV2(&vf, nil, nil, linkCaptureTimeoutInMs) == FrameTypeVideo {
+			l.to.SendVideoV2(&vf)
+			l.from.FreeVideoV2(&vf)
+		}
+	}
+}
+
+//Close destroys every component of the pipeline.
+func (p *Pipeline) Close() {
+	for name, inst := range p.Receivers {
+		inst.Destroy()
+		delete(p.Receivers, name)
+	}
+	for name, inst := range p.Senders {
+		inst.Destroy()
+		delete(p.Senders, name)
+	}
+	for name, inst := range p.Routers {
+		inst.Destroy()
+		delete(p.Routers, name)
+	}
+}
@@ -0,0 +1,74 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestPipelineConfigValidate(t *testing.T) {
+	var cfg PipelineConfig
+	doc := `{
+		"senders": [{"name": "program", "groups": "studio"}],
+		"receivers": [
+			{"name": "cam1", "source": "CAM1 (Chan 1)", "bandwidth": 100},
+			{"name": "cam2", "source": "CAM2 (Chan 1)", "bandwidth": 0}
+		]
+	}`
+	if err := json.Unmarshal([]byte(doc), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Receivers[1].Name = "program"
+	checkPipelineConfigError(t, cfg.Validate(), "program", duplicateNameErr)
+
+	cfg.Receivers[1].Name = "cam2"
+	cfg.Receivers[1].Bandwidth = 42
+	checkPipelineConfigError(t, cfg.Validate(), "cam2", bandwidthErr)
+
+	cfg.Receivers[1].Bandwidth = RecvBandwidthLowest
+	cfg.Receivers[0].Source = ""
+	checkPipelineConfigError(t, cfg.Validate(), "cam1", missingSourceErr)
+
+	cfg.Receivers[0].Source = "CAM1 (Chan 1)"
+	cfg.Routing = []RoutingConfig{{Name: "cam2"}}
+	checkPipelineConfigError(t, cfg.Validate(), "cam2", duplicateNameErr)
+
+	cfg.Routing[0].Name = ""
+	checkPipelineConfigError(t, cfg.Validate(), "", missingNameErr)
+
+	cfg.Routing[0].Name = "STUDIO-A"
+	cfg.Senders = append(cfg.Senders, SenderConfig{Name: "preview"})
+	cfg.Links = []LinkConfig{{From: "cam1", To: "program"}, {From: "cam2", To: "preview"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Links[1].From = "cam1"
+	checkPipelineConfigError(t, cfg.Validate(), "cam1->preview", duplicateLinkErr)
+
+	cfg.Links[1] = LinkConfig{From: "cam2", To: "program"}
+	checkPipelineConfigError(t, cfg.Validate(), "cam2->program", duplicateLinkErr)
+
+	cfg.Links[1].To = "STUDIO-A"
+	checkPipelineConfigError(t, cfg.Validate(), "cam2->STUDIO-A", unknownLinkErr)
+}
+
+func checkPipelineConfigError(t *testing.T, err error, name string, expected error) {
+	t.Helper()
+
+	var cfgErr *PipelineConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("Expected a PipelineConfigError but got %v.", err)
+	}
+	if cfgErr.Name != name || !errors.Is(err, expected) {
+		t.Errorf("Expected error %q for component %q but got %v.", expected, name, err)
+	}
+}
@@ -0,0 +1,137 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"math"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	exposureV2UnsupportedErr = errors.New("the loaded NDI runtime has no recv_ptz_exposure_manual_v2, it needs version 4.5 or later")
+	ptzRangeErr              = errors.New("PTZ value out of range")
+)
+
+//Packs the float arguments of a PTZ call for syscall.Syscall6. On amd64 the first four arguments are passed in
+//the SSE registers if they are floats, which the syscall package fills with the same bits as the integer
+//registers, so each float goes in as the uintptr of its bits. The receiver takes the first argument, which
+//leaves room for three floats.
+func packPTZArgs(args ...float32) [3]uintptr {
+	var a [3]uintptr
+	for i, v := range args {
+		a[i] = uintptr(math.Float32bits(v))
+	}
+	return a
+}
+
+//Checks that every value is within min and max, which also rejects NaN.
+func checkPTZRange(min, max float32, values ...float32) error {
+	for _, v := range values {
+		if !(v >= min && v <= max) {
+			return ptzRangeErr
+		}
+	}
+	return nil
+}
+
+//Calls a PTZ function of the receiver that takes up to three float arguments and returns a bool, see
+//packPTZArgs. The result is all that is reported, the SDK returns false if the source does not support PTZ and
+//leaves the last error alone.
+func (inst *RecvInstance) ptzCall(fn uintptr, args ...float32) bool {
+	a := packPTZArgs(args...)
+	ret, _, _ := syscall.Syscall6(fn, uintptr(1+len(args)), uintptr(unsafe.Pointer(inst)), a[0], a[1], a[2], 0, 0)
+	return byte(ret) != 0
+}
+
+//Whether the source this receiver is connected to supports PTZ control.
+func (inst *RecvInstance) PTZIsSupported() bool {
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzIsSupported)
+}
+
+//Moves the camera to an absolute position, pan from -1 (left) to 1 (right) and tilt from -1 (down) to 1 (up).
+//Values out of range are not sent and return an error. The bool reports whether the source accepted the command.
+func (inst *RecvInstance) PTZPanTilt(pan, tilt float32) (bool, error) {
+	if err := checkPTZRange(-1, 1, pan, tilt); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzPanTilt, pan, tilt), nil
+}
+
+//Zooms to an absolute position, from 0 (zoomed in) to 1 (zoomed out). Like PTZPanTilt, values out of range
+//return an error.
+func (inst *RecvInstance) PTZZoom(zoom float32) (bool, error) {
+	if err := checkPTZRange(0, 1, zoom); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzZoom, zoom), nil
+}
+
+//Focuses to an absolute distance, from 0 (infinity) to 1 (as close as possible). Like PTZPanTilt, values out of
+//range return an error.
+func (inst *RecvInstance) PTZFocus(focus float32) (bool, error) {
+	if err := checkPTZRange(0, 1, focus); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzFocus, focus), nil
+}
+
+//Switches the camera to auto focus, until PTZFocus or PTZFocusSpeed is used.
+func (inst *RecvInstance) PTZAutoFocus() bool {
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzAutoFocus)
+}
+
+//Moves the camera at the given speeds, from -1 (left, down) to 1 (right, up). Zero stops. Like PTZPanTilt,
+//speeds out of range return an error.
+func (inst *RecvInstance) PTZPanTiltSpeed(panSpeed, tiltSpeed float32) (bool, error) {
+	if err := checkPTZRange(-1, 1, panSpeed, tiltSpeed); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzPanTiltSpeed, panSpeed, tiltSpeed), nil
+}
+
+//Zooms at the given speed, from -1 (out) to 1 (in). Zero stops. Like PTZPanTilt, speeds out of range return an
+//error.
+func (inst *RecvInstance) PTZZoomSpeed(zoomSpeed float32) (bool, error) {
+	if err := checkPTZRange(-1, 1, zoomSpeed); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzZoomSpeed, zoomSpeed), nil
+}
+
+//Focuses at the given speed, from -1 (far) to 1 (near). Zero stops. Like PTZPanTilt, speeds out of range return
+//an error.
+func (inst *RecvInstance) PTZFocusSpeed(focusSpeed float32) (bool, error) {
+	if err := checkPTZRange(-1, 1, focusSpeed); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzFocusSpeed, focusSpeed), nil
+}
+
+//Switches the camera to automatic exposure.
+func (inst *RecvInstance) PTZExposureAuto() bool {
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzExposureAuto)
+}
+
+//Sets the exposure manually, from 0 (dark) to 1 (light). Like PTZPanTilt, values out of range return an error.
+func (inst *RecvInstance) PTZExposureManual(level float32) (bool, error) {
+	if err := checkPTZRange(0, 1, level); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzExposureManual, level), nil
+}
+
+//Sets iris, gain and shutter speed separately, each from 0 to 1. Values out of range return an error, as does a
+//runtime older than 4.5, which lacks the call.
+func (inst *RecvInstance) PTZExposureManualV2(iris, gain, shutterSpeed float32) (bool, error) {
+	if funcPtrs.NDIlibRecvPtzExposureManualV2 == 0 {
+		return false, exposureV2UnsupportedErr
+	}
+	if err := checkPTZRange(0, 1, iris, gain, shutterSpeed); err != nil {
+		return false, err
+	}
+	return inst.ptzCall(funcPtrs.NDIlibRecvPtzExposureManualV2, iris, gain, shutterSpeed), nil
+}
@@ -0,0 +1,39 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCheckPTZRange(t *testing.T) {
+	nan := float32(math.NaN())
+	tests := []struct {
+		min, max float32
+		values   []float32
+		err      error
+	}{
+		{-1, 1, []float32{-1, 0, 1}, nil},
+		{-1, 1, []float32{0, 1.01}, ptzRangeErr},
+		{0, 1, []float32{-0.1}, ptzRangeErr},
+		{-1, 1, []float32{nan}, ptzRangeErr},
+		{0, 1, nil, nil},
+	}
+	for _, test := range tests {
+		if err := checkPTZRange(test.min, test.max, test.values...); err != test.err {
+			t.Errorf("Expected %v for %v in %v..%v but got %v.", test.err, test.values, test.min, test.max, err)
+		}
+	}
+}
+
+func TestPackPTZArgs(t *testing.T) {
+	if a := packPTZArgs(); a != [3]uintptr{} {
+		t.Errorf("Expected no arguments but got %#x.", a)
+	}
+	if a := packPTZArgs(1, -0.5, 0.25); a != [3]uintptr{0x3f800000, 0xbf000000, 0x3e800000} {
+		t.Errorf("Expected the bits of 1, -0.5 and 0.25 but got %#x.", a)
+	}
+}
@@ -0,0 +1,181 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+var (
+	unknownPTZProfileErr = errors.New("unknown PTZ speed profile")
+	ptzUnsupportedErr    = errors.New("source does not accept PTZ commands")
+)
+
+//PTZSpeedProfile holds the factors, each from 0 to 1, that the speeds of the moves made through a
+//PTZProfileManager are multiplied by. A profile never moves the camera by itself.
+type PTZSpeedProfile struct {
+	Pan   float32 `json:"pan"`
+	Tilt  float32 `json:"tilt"`
+	Zoom  float32 `json:"zoom"`
+	Focus float32 `json:"focus"`
+}
+
+//The profile used for receivers that no profile was applied to, it leaves the speeds as they are.
+var fullSpeedProfile = PTZSpeedProfile{1, 1, 1, 1}
+
+//The parts of RecvInstance that PTZProfileManager uses.
+type ptzSpeedTarget interface {
+	PTZPanTiltSpeed(panSpeed, tiltSpeed float32) (bool, error)
+	PTZZoomSpeed(zoomSpeed float32) (bool, error)
+	PTZFocusSpeed(focusSpeed float32) (bool, error)
+}
+
+//PTZProfileManager keeps named speed profiles, so that operators can move cameras at agreed speeds.
+type PTZProfileManager struct {
+	mu       sync.Mutex
+	profiles map[string]PTZSpeedProfile
+	applied  map[ptzSpeedTarget]PTZSpeedProfile
+}
+
+//NewPTZProfileManager returns a manager holding the Slow, Normal and Fast profiles.
+func NewPTZProfileManager() *PTZProfileManager {
+	return &PTZProfileManager{profiles: map[string]PTZSpeedProfile{
+		"Slow":   {0.1, 0.1, 0.1, 0.1},
+		"Normal": {0.5, 0.5, 0.5, 0.5},
+		"Fast":   fullSpeedProfile,
+	}}
+}
+
+//Set adds or replaces a profile. Receivers the profile was applied to keep the factors they were given.
+func (m *PTZProfileManager) Set(name string, p PTZSpeedProfile) {
+	m.mu.Lock()
+	m.profiles[name] = p
+	m.mu.Unlock()
+}
+
+func (m *PTZProfileManager) Get(name string) (PTZSpeedProfile, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.profiles[name]
+	return p, ok
+}
+
+//Apply makes the moves of recv made through PanTiltSpeed, ZoomSpeed and FocusSpeed use the factors of the named
+//profile. No command is sent to the camera.
+func (m *PTZProfileManager) Apply(name string, recv *RecvInstance) error {
+	return m.apply(name, recv)
+}
+
+//Forget drops the profile applied to recv, call it before recv is destroyed.
+func (m *PTZProfileManager) Forget(recv *RecvInstance) {
+	m.mu.Lock()
+	delete(m.applied, recv)
+	m.mu.Unlock()
+}
+
+func (m *PTZProfileManager) apply(name string, target ptzSpeedTarget) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.profiles[name]
+	if !ok {
+		return unknownPTZProfileErr
+	}
+	if err := checkPTZRange(0, 1, p.Pan, p.Tilt, p.Zoom, p.Focus); err != nil {
+		return err
+	}
+
+	if m.applied == nil {
+		m.applied = make(map[ptzSpeedTarget]PTZSpeedProfile)
+	}
+	m.applied[target] = p
+	return nil
+}
+
+func (m *PTZProfileManager) profileOf(target ptzSpeedTarget) PTZSpeedProfile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.applied[target]; ok {
+		return p
+	}
+	return fullSpeedProfile
+}
+
+//PanTiltSpeed moves the camera behind recv like RecvInstance.PTZPanTiltSpeed, with the speeds scaled by the
+//profile applied to it.
+func (m *PTZProfileManager) PanTiltSpeed(recv *RecvInstance, panSpeed, tiltSpeed float32) error {
+	return m.panTiltSpeed(recv, panSpeed, tiltSpeed)
+}
+
+//ZoomSpeed zooms the camera behind recv like RecvInstance.PTZZoomSpeed, with the speed scaled by the profile
+//applied to it.
+func (m *PTZProfileManager) ZoomSpeed(recv *RecvInstance, zoomSpeed float32) error {
+	return m.zoomSpeed(recv, zoomSpeed)
+}
+
+//FocusSpeed focuses the camera behind recv like RecvInstance.PTZFocusSpeed, with the speed scaled by the profile
+//applied to it.
+func (m *PTZProfileManager) FocusSpeed(recv *RecvInstance, focusSpeed float32) error {
+	return m.focusSpeed(recv, focusSpeed)
+}
+
+func (m *PTZProfileManager) panTiltSpeed(target ptzSpeedTarget, panSpeed, tiltSpeed float32) error {
+	if err := checkPTZRange(-1, 1, panSpeed, tiltSpeed); err != nil {
+		return err
+	}
+	p := m.profileOf(target)
+	return ptzResult(target.PTZPanTiltSpeed(panSpeed*p.Pan, tiltSpeed*p.Tilt))
+}
+
+func (m *PTZProfileManager) zoomSpeed(target ptzSpeedTarget, zoomSpeed float32) error {
+	if err := checkPTZRange(-1, 1, zoomSpeed); err != nil {
+		return err
+	}
+	return ptzResult(target.PTZZoomSpeed(zoomSpeed * m.profileOf(target).Zoom))
+}
+
+func (m *PTZProfileManager) focusSpeed(target ptzSpeedTarget, focusSpeed float32) error {
+	if err := checkPTZRange(-1, 1, focusSpeed); err != nil {
+		return err
+	}
+	return ptzResult(target.PTZFocusSpeed(focusSpeed * m.profileOf(target).Focus))
+}
+
+//Turns the result of a PTZ call into an error, ptzUnsupportedErr if the source did not accept it.
+func ptzResult(ok bool, err error) error {
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ptzUnsupportedErr
+	}
+	return nil
+}
+
+//Save writes all profiles to w as a JSON object keyed by name.
+func (m *PTZProfileManager) Save(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return json.NewEncoder(w).Encode(m.profiles)
+}
+
+//Load reads profiles written by Save from r. They are added to the current profiles, replacing those of the
+//same name.
+func (m *PTZProfileManager) Load(r io.Reader) error {
+	var profiles map[string]PTZSpeedProfile
+	if err := json.NewDecoder(r).Decode(&profiles); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name, p := range profiles {
+		m.profiles[name] = p
+	}
+	return nil
+}
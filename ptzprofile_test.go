@@ -0,0 +1,108 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"bytes"
+	"testing"
+)
+
+type fakePTZCamera struct {
+	supported bool
+	err       error
+	speeds    PTZSpeedProfile
+}
+
+func (c *fakePTZCamera) PTZPanTiltSpeed(pan, tilt float32) (bool, error) {
+	c.speeds.Pan, c.speeds.Tilt = pan, tilt
+	return c.supported, c.err
+}
+
+func (c *fakePTZCamera) PTZZoomSpeed(zoom float32) (bool, error) {
+	c.speeds.Zoom = zoom
+	return c.supported, c.err
+}
+
+func (c *fakePTZCamera) PTZFocusSpeed(focus float32) (bool, error) {
+	c.speeds.Focus = focus
+	return c.supported, c.err
+}
+
+func TestPTZProfileManager(t *testing.T) {
+	m := NewPTZProfileManager()
+	custom := PTZSpeedProfile{0.2, 0.3, 0.4, 0}
+	m.Set("Custom", custom)
+
+	var buf bytes.Buffer
+	if err := m.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &PTZProfileManager{profiles: make(map[string]PTZSpeedProfile)}
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Slow", "Normal", "Fast", "Custom"} {
+		expected, _ := m.Get(name)
+		if p, ok := loaded.Get(name); !ok || p != expected {
+			t.Errorf("Expected profile %s to be %+v but got %+v.", name, expected, p)
+		}
+	}
+
+	camera := &fakePTZCamera{supported: true}
+	if err := loaded.apply("Custom", camera); err != nil || camera.speeds != (PTZSpeedProfile{}) {
+		t.Errorf("Expected applying a profile to leave the camera alone but got %+v (%v).", camera.speeds, err)
+	}
+	if err := loaded.apply("Unknown", camera); err != unknownPTZProfileErr {
+		t.Errorf("Expected %v but got %v.", unknownPTZProfileErr, err)
+	}
+
+	m.Set("Broken", PTZSpeedProfile{Pan: 2})
+	if err := m.apply("Broken", camera); err != ptzRangeErr {
+		t.Errorf("Expected %v but got %v.", ptzRangeErr, err)
+	}
+}
+
+func TestPTZProfileSpeeds(t *testing.T) {
+	m := NewPTZProfileManager()
+	m.Set("Custom", PTZSpeedProfile{0.5, 0.25, 0.75, 0})
+
+	//Moves are not scaled until a profile is applied.
+	camera, other := &fakePTZCamera{supported: true}, &fakePTZCamera{supported: true}
+	if err := m.panTiltSpeed(camera, 1, -1); err != nil || camera.speeds != (PTZSpeedProfile{Pan: 1, Tilt: -1}) {
+		t.Errorf("Expected the camera to move at full speed but got %+v (%v).", camera.speeds, err)
+	}
+
+	if err := m.apply("Custom", camera); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.panTiltSpeed(camera, 1, -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.zoomSpeed(camera, -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.focusSpeed(camera, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := (PTZSpeedProfile{0.5, -0.25, -0.75, 0}); camera.speeds != want {
+		t.Errorf("Expected the camera to move at %+v but got %+v.", want, camera.speeds)
+	}
+
+	//The profile belongs to the camera it was applied to.
+	if err := m.zoomSpeed(other, 0.5); err != nil || other.speeds.Zoom != 0.5 {
+		t.Errorf("Expected the other camera to zoom at 0.5 but got %v (%v).", other.speeds.Zoom, err)
+	}
+
+	if err := m.panTiltSpeed(camera, 1.5, 0); err != ptzRangeErr {
+		t.Errorf("Expected %v but got %v.", ptzRangeErr, err)
+	}
+	if err := m.zoomSpeed(&fakePTZCamera{}, 1); err != ptzUnsupportedErr {
+		t.Errorf("Expected %v but got %v.", ptzUnsupportedErr, err)
+	}
+	if err := m.focusSpeed(&fakePTZCamera{supported: true, err: ptzRangeErr}, 1); err != ptzRangeErr {
+		t.Errorf("Expected the error of the camera but got %v.", err)
+	}
+}
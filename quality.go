@@ -0,0 +1,118 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type QualityLevel int
+
+const (
+	QualityUnknown QualityLevel = iota
+	QualityGood
+	QualityDegraded
+	QualityPoor
+	QualityDisconnected
+)
+
+//Fraction of dropped video frames from which the connection counts as degraded or poor.
+const (
+	qualityDegradedDropRatio = 0.01
+	qualityPoorDropRatio     = 0.1
+)
+
+func (l QualityLevel) String() string {
+	switch l {
+	case QualityGood:
+		return "good"
+	case QualityDegraded:
+		return "degraded"
+	case QualityPoor:
+		return "poor"
+	case QualityDisconnected:
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+type QualityEvent struct {
+	Level  QualityLevel
+	Reason string
+
+	//The frame counters at the time of the event.
+	Metrics, Dropped RecvPerformance
+}
+
+//Classifies the connection from the frame counters accumulated over one monitoring interval.
+func qualityLevel(connected bool, total, dropped RecvPerformance) (QualityLevel, string) {
+	if !connected {
+		return QualityDisconnected, "not connected to a source"
+	}
+
+	//Audio only receivers have no video frames to go by.
+	frames, lost := total.VideoFrames, dropped.VideoFrames
+	if frames == 0 && lost == 0 {
+		frames, lost = total.AudioFrames, dropped.AudioFrames
+	}
+	if frames == 0 {
+		return QualityPoor, "no frames received"
+	}
+
+	ratio := float64(lost) / float64(frames)
+	reason := fmt.Sprintf("%d of %d frames dropped", lost, frames)
+	switch {
+	case ratio >= qualityPoorDropRatio:
+		return QualityPoor, reason
+	case ratio >= qualityDegradedDropRatio:
+		return QualityDegraded, reason
+	}
+	return QualityGood, reason
+}
+
+func subPerformance(a, b RecvPerformance) RecvPerformance {
+	return RecvPerformance{a.VideoFrames - b.VideoFrames, a.AudioFrames - b.AudioFrames, a.MetadataFrames - b.MetadataFrames}
+}
+
+//MonitorQuality samples the performance counters of the receiver every interval and emits an event whenever the
+//quality level changes. The channel is closed once ctx is done.
+func (inst *RecvInstance) MonitorQuality(ctx context.Context, interval time.Duration) <-chan QualityEvent {
+	events := make(chan QualityEvent)
+
+	go func() {
+		defer close(events)
+
+		ticker := sysClock.NewTicker(interval)
+		defer ticker.Stop()
+
+		prevTotal, prevDropped := inst.GetPerformance()
+		level := QualityUnknown
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+
+			total, dropped := inst.GetPerformance()
+			n, _ := inst.GetNumConnections(0)
+			l, reason := qualityLevel(n > 0, subPerformance(total, prevTotal), subPerformance(dropped, prevDropped))
+			prevTotal, prevDropped = total, dropped
+			if l == level {
+				continue
+			}
+			level = l
+
+			select {
+			case events <- QualityEvent{l, reason, total, dropped}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
@@ -0,0 +1,28 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestQualityLevel(t *testing.T) {
+	tests := []struct {
+		connected      bool
+		total, dropped RecvPerformance
+		level          QualityLevel
+	}{
+		{false, RecvPerformance{VideoFrames: 60}, RecvPerformance{}, QualityDisconnected},
+		{true, RecvPerformance{}, RecvPerformance{}, QualityPoor},
+		{true, RecvPerformance{VideoFrames: 1000}, RecvPerformance{VideoFrames: 5}, QualityGood},
+		{true, RecvPerformance{VideoFrames: 1000}, RecvPerformance{VideoFrames: 50}, QualityDegraded},
+		{true, RecvPerformance{VideoFrames: 1000}, RecvPerformance{VideoFrames: 200}, QualityPoor},
+		{true, RecvPerformance{AudioFrames: 100}, RecvPerformance{AudioFrames: 20}, QualityPoor},
+	}
+
+	for i, tt := range tests {
+		if l, reason := qualityLevel(tt.connected, tt.total, tt.dropped); l != tt.level {
+			t.Errorf("Test %d: expected %v but got %v (%s).", i, tt.level, l, reason)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+//ErrRateLimited is returned by RateLimitedSender.Send when the frame was not sent because the rate limit was reached.
+var ErrRateLimited = errors.New("send rate limit reached")
+
+//tokenBucket allows on average rate events per second with bursts of up to burst events.
+type tokenBucket struct {
+	rate, burst float64
+	tokens      float64
+	last        time.Time
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+//RateLimitedSender caps the rate at which video frames are passed to a SendInstance.
+type RateLimitedSender struct {
+	inst *SendInstance
+
+	mu     sync.Mutex
+	bucket tokenBucket
+	clock  clock
+}
+
+func NewRateLimitedSender(inst *SendInstance, framesPerSec float64) *RateLimitedSender {
+	return &RateLimitedSender{
+		inst:   inst,
+		bucket: tokenBucket{rate: framesPerSec, burst: 1, tokens: 1},
+		clock:  sysClock,
+	}
+}
+
+//Send sends vf if the rate limit allows it, otherwise it returns ErrRateLimited and the caller may skip or buffer the frame.
+func (s *RateLimitedSender) Send(vf *VideoFrameV2) error {
+	if !s.allow() {
+		return ErrRateLimited
+	}
+
+	s.inst.SendVideoV2(vf)
+	return nil
+}
+
+func (s *RateLimitedSender) allow() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bucket.take(s.clock.Now())
+}
@@ -0,0 +1,26 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := tokenBucket{rate: 10, burst: 1, tokens: 1}
+	start := time.Unix(0, 0)
+
+	//Offer frames at 20fps for one second, half of them must get through.
+	var sent int
+	for i := 0; i < 20; i++ {
+		if b.take(start.Add(time.Duration(i) * 50 * time.Millisecond)) {
+			sent++
+		}
+	}
+	if sent != 10 {
+		t.Errorf("Expected 10 frames to pass but %d did.", sent)
+	}
+}
@@ -5,10 +5,19 @@
 package ndi
 
 import (
+	"context"
+	"errors"
+	"math"
 	"syscall"
 	"unsafe"
 )
 
+var (
+	connectionLostErr  = errors.New("connection to the source was lost")
+	notConnectedErr    = errors.New("receiver is not connected to a source")
+	frameStillOwnedErr = errors.New("frame still holds captured data, free it first")
+)
+
 type RecvInstance struct{}
 
 func NewRecvInstanceV2(settings *RecvCreateSettings) *RecvInstance {
@@ -19,12 +28,25 @@ func NewRecvInstanceV2(settings *RecvCreateSettings) *RecvInstance {
 	return (*RecvInstance)(unsafe.Pointer(ret))
 }
 
+//Creates a receiver for the given settings, returns nil if the SDK could not create it. Same as NewRecvInstanceV2,
+//which is kept for callers that follow the SDK's naming.
+func NewRecvInstance(settings *RecvCreateSettings) *RecvInstance {
+	return NewRecvInstanceV2(settings)
+}
+
 func (inst *RecvInstance) Destroy() {
 	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvDestroy, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
 		panic(eno)
 	}
 }
 
+//Switches the receiver to another source. Passing nil disconnects it.
+func (inst *RecvInstance) Connect(source *Source) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvConnect, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(source)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
 //Set the up-stream tally notifications. This returns FALSE if we are not currently connected to anything. That
 //said, the moment that we do connect to something it will automatically be sent the tally state.
 func (inst *RecvInstance) SetTally(tally *Tally) bool {
@@ -32,7 +54,16 @@ func (inst *RecvInstance) SetTally(tally *Tally) bool {
 	if eno != 0 {
 		panic(eno)
 	}
-	return ret != 0
+	return byte(ret) != 0
+}
+
+//Like SetTally, but reports not being connected as an error so that callers know to retry after a reconnect. It
+//is safe to call right after creating the receiver, the state is then sent once the source is connected.
+func (inst *RecvInstance) ReportTally(tally Tally) error {
+	if !inst.SetTally(&tally) {
+		return notConnectedErr
+	}
+	return nil
 }
 
 //This function will send a meta message to the source that we are connected too. This returns FALSE if we are
@@ -45,6 +76,25 @@ func (inst *RecvInstance) SendMetadata(mf *MetadataFrame) bool {
 	return ret != 0
 }
 
+//Add to the list of connection metadata that is sent to every source this receiver connects to, e.g. product
+//names or capabilities. The metadata is copied, so mf can be reused afterwards.
+//
+//The SDK does not synchronize the connection metadata with capturing. This must not be called while another goroutine
+//is inside CaptureV2, SendMetadata or any other call on inst, and no locking is done here to prevent that.
+func (inst *RecvInstance) AddConnectionMetadata(mf *MetadataFrame) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvAddConnectionMetadata, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Removes all connection metadata added with AddConnectionMetadata. Safe to call when none was added.
+//The same threading restriction as for AddConnectionMetadata applies.
+func (inst *RecvInstance) ClearConnectionMetadata() {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvClearConnectionMetadata, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
+		panic(eno)
+	}
+}
+
 func (inst *RecvInstance) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) FrameType {
 	ret, _, _ := syscall.Syscall6(
 		funcPtrs.NDIlibRecvCaptureV2,
@@ -60,6 +110,80 @@ func (inst *RecvInstance) CaptureV2(vf *VideoFrameV2, af *AudioFrameV2, mf *Meta
 	return FrameType(ret)
 }
 
+//Like CaptureV2, but captures audio in the NDI 4 format. Free audio frames with FreeAudioV3.
+func (inst *RecvInstance) CaptureV3(vf *VideoFrameV2, af *AudioFrameV3, mf *MetadataFrame, timeoutInMs uint32) FrameType {
+	ret, _, _ := syscall.Syscall6(
+		funcPtrs.NDIlibFrameTypeE,
+		5,
+		uintptr(unsafe.Pointer(inst)),
+		uintptr(unsafe.Pointer(vf)),
+		uintptr(unsafe.Pointer(af)),
+		uintptr(unsafe.Pointer(mf)),
+		uintptr(timeoutInMs),
+		0,
+	)
+
+	return FrameType(ret)
+}
+
+//Captures a frame like CaptureV2, but reports a lost connection (FrameTypeError) as an error. A frame returned in
+//vf, af or mf must be freed with FreeVideo, FreeAudio or FreeMetadata before the struct is passed to Capture again,
+//otherwise Capture refuses to overwrite it and returns an error rather than leaking the SDK's memory.
+func (inst *RecvInstance) Capture(vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) (FrameType, error) {
+	if vf != nil && vf.Data != nil || af != nil && af.Data != nil || mf != nil && mf.Data != nil {
+		return FrameTypeNone, frameStillOwnedErr
+	}
+
+	ft := inst.CaptureV2(vf, af, mf, timeoutInMs)
+	if ft == FrameTypeError {
+		return ft, connectionLostErr
+	}
+	return ft, nil
+}
+
+//Captures a frame like CaptureV2, but returns ctx.Err() as soon as ctx is done instead of blocking for the full
+//timeout. The SDK call is split into short polls, so cancellation is noticed within about 50ms.
+func (inst *RecvInstance) CaptureV2WithContext(ctx context.Context, vf *VideoFrameV2, af *AudioFrameV2, mf *MetadataFrame, timeoutInMs uint32) (FrameType, error) {
+	return captureWithContext(ctx, timeoutInMs, func(timeoutInMs uint32) FrameType {
+		return inst.CaptureV2(vf, af, mf, timeoutInMs)
+	})
+}
+
+//Captures a video frame and then drains every video frame that is already queued behind it, freeing the stale
+//ones, so that vf always ends up holding the newest frame. Returns the frame type of the first capture and the
+//number of frames that were skipped. Audio and metadata are not captured. Free vf with FreeVideoV2 as usual.
+func (inst *RecvInstance) CaptureLatest(vf *VideoFrameV2, timeoutInMs uint32) (FrameType, int) {
+	return captureLatest(inst, vf, timeoutInMs)
+}
+
+//Frees a video frame returned by Capture. Frames without data are ignored and the frame is reset afterwards, so
+//it can be passed to Capture again and freeing it twice is harmless.
+func (inst *RecvInstance) FreeVideo(vf *VideoFrameV2) {
+	if vf == nil || vf.Data == nil {
+		return
+	}
+	inst.FreeVideoV2(vf)
+	vf.Data, vf.Metadata = nil, nil
+}
+
+//Frees an audio frame returned by Capture, see FreeVideo.
+func (inst *RecvInstance) FreeAudio(af *AudioFrameV2) {
+	if af == nil || af.Data == nil {
+		return
+	}
+	inst.FreeAudioV2(af)
+	af.Data, af.Metadata = nil, nil
+}
+
+//Frees a metadata frame returned by Capture, see FreeVideo.
+func (inst *RecvInstance) FreeMetadata(mf *MetadataFrame) {
+	if mf == nil || mf.Data == nil {
+		return
+	}
+	inst.FreeMetadataV2(mf)
+	mf.Data = nil
+}
+
 func (inst *RecvInstance) FreeVideoV2(vf *VideoFrameV2) {
 	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvFreeVideoV2, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(vf)), 0); eno != 0 {
 		panic(eno)
@@ -72,6 +196,12 @@ func (inst *RecvInstance) FreeAudioV2(af *AudioFrameV2) {
 	}
 }
 
+func (inst *RecvInstance) FreeAudioV3(af *AudioFrameV3) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvFreeAudioV3, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(af)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
 func (inst *RecvInstance) FreeMetadataV2(mf *MetadataFrame) {
 	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvFreeMetadata, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), 0); eno != 0 {
 		panic(eno)
@@ -80,10 +210,156 @@ func (inst *RecvInstance) FreeMetadataV2(mf *MetadataFrame) {
 
 //Is this receiver currently connected to a source on the other end, or has the source not yet been found or is no longe ronline.
 //This will normally return 0 or 1.
+//
+//Deprecated: the SDK call does not wait, timeoutInMs is ignored. Use GetNoConnections.
 func (inst *RecvInstance) GetNumConnections(timeoutInMs uint32) (int, error) {
-	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvGetNoConnections, 2, uintptr(unsafe.Pointer(inst)), uintptr(timeoutInMs), 0)
+	return inst.getNoConnections()
+}
+
+//Returns the number of sources this receiver is connected to, normally 0 or 1, without waiting. Returns zero if the
+//connections cannot be queried.
+func (inst *RecvInstance) GetNoConnections() int {
+	n, err := inst.getNoConnections()
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func (inst *RecvInstance) getNoConnections() (int, error) {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvGetNoConnections, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
 	if eno != 0 {
 		return 0, Error{eno}
 	}
 	return int(ret), nil
 }
+
+//Get the current performance structures. This can be used to determine if you have been calling CaptureV2 fast
+//enough, or if your processing of data is not keeping up with real-time. The total structure will give you the total
+//frame counts received, the dropped structure will tell you how many frames have been dropped.
+func (inst *RecvInstance) GetPerformance() (total, dropped RecvPerformance) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvGetPerformance, 3, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(&total)), uintptr(unsafe.Pointer(&dropped))); eno != 0 {
+		panic(eno)
+	}
+	return
+}
+
+//This will allow you to determine the current queue depth for all of the frame sources at any time.
+func (inst *RecvInstance) GetQueue() RecvQueue {
+	var queue RecvQueue
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvGetQueue, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(&queue)), 0); eno != 0 {
+		panic(eno)
+	}
+	return queue
+}
+
+//Copies a string owned by this receiver into Go memory and hands it back to the SDK. Every const char* that the
+//receiver API returns must go through here so that it is not leaked.
+func (inst *RecvInstance) takeString(p uintptr) string {
+	if p == 0 {
+		return ""
+	}
+
+	s := goStringFromCString(p)
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvFreeString, 2, uintptr(unsafe.Pointer(inst)), p, 0); eno != 0 {
+		panic(eno)
+	}
+	return s
+}
+
+//Returns the last error text the SDK reported for this receiver, or an empty string if there is none.
+//Currently only failures of the recording API are reported this way.
+func (inst *RecvInstance) LastSDKError() string {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingGetError, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return inst.takeString(ret)
+}
+
+//Builds the error for a failed receiver call, attaching the SDK's own error text when it has any.
+func (inst *RecvInstance) sdkError(op string) error {
+	return &SDKError{Op: op, Message: inst.LastSDKError()}
+}
+
+//Whether the connected source supports recording. This may only be known once the receiver is connected.
+func (inst *RecvInstance) RecordingIsSupported() bool {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingIsSupported, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return byte(ret) != 0
+}
+
+//Starts recording on the source. The recording happens on the machine of the source, filenameHint is used as the
+//base of the file name if it is not empty, the name actually used is returned by RecordingGetFilename.
+func (inst *RecvInstance) RecordingStart(filenameHint string) error {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingStart, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(cString(filenameHint))), 0)
+	if eno != 0 {
+		return Error{eno}
+	}
+	if byte(ret) == 0 {
+		return inst.sdkError("recording start")
+	}
+	return nil
+}
+
+//Stops the recording.
+func (inst *RecvInstance) RecordingStop() error {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingStop, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		return Error{eno}
+	}
+	if byte(ret) == 0 {
+		return inst.sdkError("recording stop")
+	}
+	return nil
+}
+
+//Sets the gain applied to the recorded audio in dB, it may be changed while recording.
+func (inst *RecvInstance) RecordingSetAudioLevel(dB float32) error {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingSetAudioLevel, 2, uintptr(unsafe.Pointer(inst)), uintptr(math.Float32bits(dB)), 0)
+	if eno != 0 {
+		return Error{eno}
+	}
+	if byte(ret) == 0 {
+		return inst.sdkError("recording set audio level")
+	}
+	return nil
+}
+
+//Whether the source is currently recording.
+func (inst *RecvInstance) RecordingIsRecording() bool {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingIsRecording, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return byte(ret) != 0
+}
+
+//Returns the name of the file being recorded to, or an empty string if there is no recording.
+func (inst *RecvInstance) RecordingGetFilename() string {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingGetFilename, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return inst.takeString(ret)
+}
+
+//Returns the last recording error, or an empty string if there is none. The same as LastSDKError.
+func (inst *RecvInstance) RecordingGetError() string {
+	return inst.LastSDKError()
+}
+
+//Returns how much has been recorded. The second result is false if the source is not recording.
+func (inst *RecvInstance) RecordingGetTimes() (RecordingTimes, bool) {
+	var times recordingTime
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRecvRecordingGetTimes, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(&times)), 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	if byte(ret) == 0 {
+		return RecordingTimes{}, false
+	}
+	return times.toGo(), true
+}
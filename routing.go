@@ -0,0 +1,181 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	routingChangeErr = errors.New("unable to change routing source")
+	routingClearErr  = errors.New("unable to clear routing source")
+
+	//ErrTimeout is returned by ChangeAndVerify when the receivers did not reconnect in time.
+	ErrTimeout = errors.New("timed out")
+)
+
+//How often ChangeAndVerify checks the connections of a routing instance.
+const routingPollInterval = 50 * time.Millisecond
+
+//A routing instance is an NDI source that redirects its receivers to another source, like a virtual patch bay.
+type RoutingInstance struct{}
+
+//RoutingChange is one entry of the change history of a routing instance.
+type RoutingChange struct {
+	Time          time.Time
+	Name, Address string
+}
+
+//The SDK handle cannot carry state, so the histories are kept here.
+var (
+	routingHistoryMu sync.Mutex
+	routingHistory   = make(map[*RoutingInstance][]RoutingChange)
+)
+
+//RoutingSettings is the Go friendly form of RoutingCreateSettings.
+type RoutingSettings struct {
+	//Name of the source that receivers connect to.
+	NdiName string
+
+	//Comma separated list of the groups the source is in, empty for the default groups.
+	Groups string
+}
+
+//Creates a routing instance from settings, see NewRoutingInstance.
+func NewRoutingInstanceFromSettings(settings *RoutingSettings) *RoutingInstance {
+	return NewRoutingInstance(&RoutingCreateSettings{cString(settings.NdiName), cString(settings.Groups)})
+}
+
+func NewRoutingInstance(settings *RoutingCreateSettings) *RoutingInstance {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRoutingCreate, 1, uintptr(unsafe.Pointer(settings)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return (*RoutingInstance)(unsafe.Pointer(ret))
+}
+
+func (inst *RoutingInstance) Destroy() {
+	inst.ClearHistory()
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibRoutingDestroy, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Change the routing of this source to another destination.
+func (inst *RoutingInstance) Change(source *Source) error {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRoutingChange, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(source)), 0)
+	if eno != 0 {
+		return Error{eno}
+	}
+	if byte(ret) == 0 {
+		return routingChangeErr
+	}
+	return nil
+}
+
+//Clear the routing, receivers of this source get no video until it is changed again.
+func (inst *RoutingInstance) Clear() error {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRoutingClear, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		return Error{eno}
+	}
+	if byte(ret) == 0 {
+		return routingClearErr
+	}
+	return nil
+}
+
+//Get the current number of receivers connected to this routing source.
+func (inst *RoutingInstance) GetNumConnections(timeoutInMs uint32) (int, error) {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibRoutingGetNoConnections, 2, uintptr(unsafe.Pointer(inst)), uintptr(timeoutInMs), 0)
+	if eno != 0 {
+		return 0, Error{eno}
+	}
+	return int(ret), nil
+}
+
+//Like GetNumConnections, but returns zero if the connections cannot be queried.
+func (inst *RoutingInstance) GetNoConnections(timeoutInMs uint32) int {
+	n, err := inst.GetNumConnections(timeoutInMs)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+//Returns the name and address that receivers use to connect to this routing source, copied out of SDK memory.
+func (inst *RoutingInstance) GetSourceName() Source {
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibSourceT, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		panic(eno)
+	}
+	return copySource(ret)
+}
+
+//ChangeAndVerify changes the routing like Change and waits until the receivers of this source have dropped off
+//and connected again, which is when they get the new source. Returns ErrTimeout if that does not happen within
+//timeout, which is always the case when no receiver is connected.
+func (inst *RoutingInstance) ChangeAndVerify(source Source, timeout time.Duration) error {
+	if err := inst.Change(&source); err != nil {
+		return err
+	}
+	return awaitReconnect(timeout, func() (int, error) {
+		return inst.GetNumConnections(0)
+	})
+}
+
+//Polls connections until it has reported zero and then more than zero connections.
+func awaitReconnect(timeout time.Duration, connections func() (int, error)) error {
+	deadline := sysClock.Now().Add(timeout)
+	dropped := false
+	for {
+		n, err := connections()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			dropped = true
+		} else if dropped {
+			return nil
+		}
+
+		if !sysClock.Now().Before(deadline) {
+			return ErrTimeout
+		}
+		sysClock.Sleep(routingPollInterval)
+	}
+}
+
+//ChangeWithHistory changes the routing like Change and records the change in the history of the instance.
+func (inst *RoutingInstance) ChangeWithHistory(source Source) error {
+	if err := inst.Change(&source); err != nil {
+		return err
+	}
+
+	change := RoutingChange{sysClock.Now(), source.Name(), source.Address()}
+
+	routingHistoryMu.Lock()
+	routingHistory[inst] = append(routingHistory[inst], change)
+	routingHistoryMu.Unlock()
+	return nil
+}
+
+//History returns the changes made through ChangeWithHistory, oldest first.
+func (inst *RoutingInstance) History() []RoutingChange {
+	routingHistoryMu.Lock()
+	defer routingHistoryMu.Unlock()
+	return append([]RoutingChange(nil), routingHistory[inst]...)
+}
+
+func (inst *RoutingInstance) ClearHistory() {
+	routingHistoryMu.Lock()
+	delete(routingHistory, inst)
+	routingHistoryMu.Unlock()
+}
@@ -0,0 +1,39 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAwaitReconnect(t *testing.T) {
+	tests := []struct {
+		name        string
+		connections []int
+		err         error
+	}{
+		{"reconnected", []int{2, 2, 0, 0, 1}, nil},
+		{"never dropped", []int{2}, ErrTimeout},
+		{"never returned", []int{2, 0}, ErrTimeout},
+	}
+
+	for _, test := range tests {
+		useFakeClock(t)
+
+		polls := test.connections
+		connections := func() (int, error) {
+			n := polls[0]
+			if len(polls) > 1 {
+				polls = polls[1:]
+			}
+			return n, nil
+		}
+
+		if err := awaitReconnect(time.Second, connections); err != test.err {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"image/color"
+)
+
+var invalidOpacityErr = errors.New("opacity must be between 0 and 1")
+
+//SafeAreaGuides selects the guides DrawSafeAreas draws.
+type SafeAreaGuides int
+
+const (
+	SafeAreaBoth SafeAreaGuides = iota
+
+	//The central 90% of the width and height, where the action is visible on every display.
+	SafeAreaAction
+
+	//The central 80% of the width and height, where text is readable on every display.
+	SafeAreaTitle
+)
+
+//Fractions of the frame size the guides cover.
+const (
+	actionSafeArea = 0.9
+	titleSafeArea  = 0.8
+)
+
+type SafeAreaOptions struct {
+	Guides SafeAreaGuides
+
+	//The color of the lines, its alpha is ignored. Zero means white.
+	Color color.NRGBA
+
+	//How opaque the lines are, from 0 to 1. Zero means 0.5.
+	Opacity float32
+
+	//The width of the lines in pixels. Zero means one pixel per 540 lines of the frame, at least one.
+	LineWidth int
+}
+
+//DrawSafeAreas overlays the action and title safe area guides on vf in place, as rectangles centered in the
+//frame. BGRA, BGRX and UYVY frames are supported.
+func DrawSafeAreas(vf *VideoFrameV2, opts SafeAreaOptions) error {
+	c, err := newOverlayCanvas(vf)
+	if err != nil {
+		return err
+	}
+
+	opacity := opts.Opacity
+	if opacity == 0 {
+		opacity = 0.5
+	}
+	if !(opacity >= 0 && opacity <= 1) {
+		return invalidOpacityErr
+	}
+	lineColor := opts.Color
+	if lineColor == (color.NRGBA{}) {
+		lineColor = color.NRGBA{255, 255, 255, 0}
+	}
+	lineColor.A = 255
+
+	width, height := int(vf.Xres), int(vf.Yres)
+	lineWidth := opts.LineWidth
+	if lineWidth <= 0 {
+		lineWidth = maxInt(1, height/540)
+	}
+
+	var areas []float32
+	switch opts.Guides {
+	case SafeAreaAction:
+		areas = []float32{actionSafeArea}
+	case SafeAreaTitle:
+		areas = []float32{titleSafeArea}
+	default:
+		areas = []float32{actionSafeArea, titleSafeArea}
+	}
+
+	for _, area := range areas {
+		insetX := int((1-area)/2*float32(width) + 0.5)
+		insetY := int((1-area)/2*float32(height) + 0.5)
+		x0, y0, x1, y1 := insetX, insetY, width-insetX, height-insetY
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				if x < x0+lineWidth || x >= x1-lineWidth || y < y0+lineWidth || y >= y1-lineWidth {
+					c.blend(x, y, lineColor, opacity)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestDrawSafeAreas(t *testing.T) {
+	vf, data := newTestVideoFrame(FourCCTypeBGRA, 200, 100, 4, func(x, y int) byte { return 0 })
+	pixel := func(x, y int) [4]byte {
+		var px [4]byte
+		copy(px[:], data[y*800+x*4:])
+		return px
+	}
+
+	if err := DrawSafeAreas(vf, SafeAreaOptions{Color: color.NRGBA{R: 255}, Opacity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	red, black := [4]byte{0, 0, 255, 255}, [4]byte{0, 0, 0, 0}
+	checks := []struct {
+		x, y int
+		want [4]byte
+	}{
+		//Action safe from 10,5 to 189,94.
+		{10, 50, red},
+		{189, 50, red},
+		{100, 5, red},
+		{100, 94, red},
+		{9, 50, black},
+		{11, 50, black},
+		//Title safe from 20,10 to 179,89.
+		{20, 50, red},
+		{179, 50, red},
+		{100, 10, red},
+		{100, 89, red},
+		{100, 50, black},
+	}
+	for _, c := range checks {
+		if px := pixel(c.x, c.y); px != c.want {
+			t.Errorf("Expected %v at %d,%d but got %v.", c.want, c.x, c.y, px)
+		}
+	}
+
+	//Only the title safe area, half transparent white on a grey UYVY frame.
+	uyvy, uyvyData := newTestVideoFrame(FourCCTypeUYVY, 200, 100, 2, func(x, y int) byte { return 0 })
+	for i := 0; i < len(uyvyData); i += 4 {
+		uyvyData[i], uyvyData[i+1], uyvyData[i+2], uyvyData[i+3] = 128, 125, 128, 125
+	}
+	if err := DrawSafeAreas(uyvy, SafeAreaOptions{Guides: SafeAreaTitle}); err != nil {
+		t.Fatal(err)
+	}
+	if luma := uyvyData[50*400+20*2+1]; luma != 180 {
+		t.Errorf("Expected luma 180 on the title safe line but got %d.", luma)
+	}
+	if luma := uyvyData[50*400+10*2+1]; luma != 125 {
+		t.Errorf("Expected no action safe line but got luma %d.", luma)
+	}
+
+	if err := DrawSafeAreas(vf, SafeAreaOptions{Opacity: 2}); err != invalidOpacityErr {
+		t.Errorf("Expected %v but got %v.", invalidOpacityErr, err)
+	}
+	if err := DrawSafeAreas(nil, SafeAreaOptions{}); err != invalidVideoFrameErr {
+		t.Errorf("Expected %v but got %v.", invalidVideoFrameErr, err)
+	}
+}
@@ -0,0 +1,55 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "math"
+
+//Relative difference between the detected and the declared sample rate above which a source counts as misconfigured.
+const sampleRateTolerance = 0.005
+
+//SampleRateDetector infers the actual sample rate of an audio stream from the timecodes and sample counts of
+//consecutive frames. Timecodes are in 100ns units, so a few frames are needed before the result is accurate.
+type SampleRateDetector struct {
+	declared    int32
+	prevTC      int64
+	prevSamples int64
+	havePrev    bool
+
+	samples, duration int64
+}
+
+//Feed adds a received frame. Frames without a real timecode are ignored and a timecode going backwards restarts detection.
+func (d *SampleRateDetector) Feed(af *AudioFrameV2) {
+	if af.Timecode == SendTimecodeSynthesize || af.NumSamples <= 0 {
+		return
+	}
+
+	if d.havePrev {
+		delta := af.Timecode - d.prevTC
+		if delta <= 0 || af.SampleRate != d.declared {
+			d.samples, d.duration = 0, 0
+		} else {
+			d.samples += d.prevSamples
+			d.duration += delta
+		}
+	}
+
+	d.declared = af.SampleRate
+	d.prevTC = af.Timecode
+	d.prevSamples = int64(af.NumSamples)
+	d.havePrev = true
+}
+
+//Detected returns the detected sample rate. The boolean is false if nothing has been detected yet or if the
+//detected rate differs from the declared SampleRate by more than 0.5%, which indicates a misconfigured source.
+func (d *SampleRateDetector) Detected() (int, bool) {
+	if d.duration == 0 {
+		return 0, false
+	}
+
+	rate := float64(d.samples) * 1e7 / float64(d.duration)
+	ok := math.Abs(rate-float64(d.declared)) <= float64(d.declared)*sampleRateTolerance
+	return int(math.Round(rate)), ok
+}
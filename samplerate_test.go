@@ -0,0 +1,37 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func feedSampleRate(d *SampleRateDetector, declared, actual int32, frames int) {
+	const numSamples = 1600
+
+	af := NewAudioFrameV2()
+	af.SampleRate = declared
+	af.NumSamples = numSamples
+	for i := 0; i < frames; i++ {
+		af.Timecode = int64(i) * numSamples * 1e7 / int64(actual)
+		d.Feed(af)
+	}
+}
+
+func TestSampleRateDetector(t *testing.T) {
+	var d SampleRateDetector
+	if _, ok := d.Detected(); ok {
+		t.Error("Expected no detection before any frames were fed.")
+	}
+
+	feedSampleRate(&d, 48000, 48000, 30)
+	if rate, ok := d.Detected(); !ok || rate != 48000 {
+		t.Errorf("Expected 48000 to be detected but got %d (%v).", rate, ok)
+	}
+
+	d = SampleRateDetector{}
+	feedSampleRate(&d, 48000, 44100, 30)
+	if rate, ok := d.Detected(); ok || rate != 44100 {
+		t.Errorf("Expected a misconfigured 44100 source to be detected but got %d (%v).", rate, ok)
+	}
+}
@@ -0,0 +1,166 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	unsupportedFourCCErr = errors.New("unsupported FourCC")
+	invalidVideoFrameErr = errors.New("invalid video frame")
+	invalidResolutionErr = errors.New("invalid target resolution")
+)
+
+type ScaleFilter int
+
+const (
+	//Interpolates between the 2x2 nearest source pixels. Cheap, but aliases when shrinking by more than half.
+	ScaleFilterBilinear ScaleFilter = iota
+
+	//Averages every source pixel covered by the target pixel, weighted by coverage (box filter).
+	//Gives sharper and alias free results when downscaling.
+	ScaleFilterArea
+)
+
+//Returns the size in bytes of a pixel unit and how many pixels it holds. UYVY shares the chroma
+//between two pixels so it is scaled in units of two pixels.
+func pixelUnit(fourCC [4]byte) (bytes, pixels int, ok bool) {
+	switch fourCC {
+	case FourCCTypeBGRA, FourCCTypeBGRX:
+		return 4, 1, true
+	case FourCCTypeUYVY:
+		return 4, 2, true
+	}
+	return 0, 0, false
+}
+
+//DownscaleVideoFrame is ScaleVideoFrame with the area averaging filter. The target must not be larger than src.
+func DownscaleVideoFrame(src *VideoFrameV2, targetX, targetY int32) (*VideoFrameV2, error) {
+	if src != nil && (targetX > src.Xres || targetY > src.Yres) {
+		return nil, invalidResolutionErr
+	}
+	return ScaleVideoFrame(src, targetX, targetY, ScaleFilterArea)
+}
+
+//ScaleVideoFrame returns a copy of src scaled to targetX by targetY using the given filter. BGRA, BGRX and UYVY
+//frames are supported, for UYVY targetX must be even. The returned frame owns its data and carries no metadata.
+func ScaleVideoFrame(src *VideoFrameV2, targetX, targetY int32, filter ScaleFilter) (*VideoFrameV2, error) {
+	if src == nil || src.Xres <= 0 || src.Yres <= 0 {
+		return nil, invalidVideoFrameErr
+	}
+
+	unitBytes, unitPixels, ok := pixelUnit(src.FourCC)
+	if !ok {
+		return nil, unsupportedFourCCErr
+	}
+	if targetX <= 0 || targetY <= 0 || int(targetX)%unitPixels != 0 || int(src.Xres)%unitPixels != 0 {
+		return nil, invalidResolutionErr
+	}
+
+	srcW, srcH := int(src.Xres)/unitPixels, int(src.Yres)
+	if int(src.LineStride) < srcW*unitBytes {
+		return nil, invalidVideoFrameErr
+	}
+	srcData := src.data()
+	if srcData == nil {
+		return nil, invalidVideoFrameErr
+	}
+
+	dstW, dstH := int(targetX)/unitPixels, int(targetY)
+	dstStride := dstW * unitBytes
+	dstData := make([]byte, dstStride*dstH)
+
+	xTaps := scaleTaps(srcW, dstW, filter)
+	yTaps := scaleTaps(srcH, dstH, filter)
+
+	//Horizontal pass into an intermediate buffer followed by a vertical pass.
+	rowLen := dstW * unitBytes
+	tmp := make([]float32, srcH*rowLen)
+	for y := 0; y < srcH; y++ {
+		srcRow := srcData[y*int(src.LineStride):]
+		tmpRow := tmp[y*rowLen : (y+1)*rowLen]
+		for x, tap := range xTaps {
+			for i, sx := range tap.index {
+				w := tap.weight[i]
+				for c := 0; c < unitBytes; c++ {
+					tmpRow[x*unitBytes+c] += w * float32(srcRow[sx*unitBytes+c])
+				}
+			}
+		}
+	}
+
+	for y, tap := range yTaps {
+		dstRow := dstData[y*dstStride : (y+1)*dstStride]
+		for x := 0; x < rowLen; x++ {
+			var v float32
+			for i, sy := range tap.index {
+				v += tap.weight[i] * tmp[sy*rowLen+x]
+			}
+			dstRow[x] = clampByte(v)
+		}
+	}
+
+	dst := *src
+	dst.Xres = targetX
+	dst.Yres = targetY
+	dst.LineStride = int32(dstStride)
+	dst.Data = &dstData[0]
+	dst.Metadata = nil
+	return &dst, nil
+}
+
+type scaleTap struct {
+	index  []int
+	weight []float32
+}
+
+//Computes for every target coordinate which source coordinates contribute and by how much.
+func scaleTaps(srcN, dstN int, filter ScaleFilter) []scaleTap {
+	scale := float64(srcN) / float64(dstN)
+	taps := make([]scaleTap, dstN)
+
+	for o := range taps {
+		switch filter {
+		case ScaleFilterArea:
+			start, end := float64(o)*scale, float64(o+1)*scale
+			for i := int(start); i < srcN && float64(i) < end; i++ {
+				w := math.Min(end, float64(i+1)) - math.Max(start, float64(i))
+				if w <= 0 {
+					continue
+				}
+				taps[o].index = append(taps[o].index, i)
+				taps[o].weight = append(taps[o].weight, float32(w/scale))
+			}
+
+		default:
+			c := (float64(o)+0.5)*scale - 0.5
+			if c < 0 {
+				c = 0
+			}
+			i0 := int(c)
+			if i0 >= srcN-1 {
+				taps[o].index = []int{srcN - 1}
+				taps[o].weight = []float32{1}
+				continue
+			}
+			f := float32(c - float64(i0))
+			taps[o].index = []int{i0, i0 + 1}
+			taps[o].weight = []float32{1 - f, f}
+		}
+	}
+	return taps
+}
+
+func clampByte(v float32) byte {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return byte(v + 0.5)
+}
@@ -0,0 +1,79 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func newTestVideoFrame(fourCC [4]byte, xres, yres, bytesPerPixel int32, pixel func(x, y int) byte) (*VideoFrameV2, []byte) {
+	data := make([]byte, xres*yres*bytesPerPixel)
+	for y := 0; y < int(yres); y++ {
+		for x := 0; x < int(xres*bytesPerPixel); x++ {
+			data[y*int(xres*bytesPerPixel)+x] = pixel(x/int(bytesPerPixel), y)
+		}
+	}
+
+	vf := NewVideoFrameV2()
+	vf.FourCC = fourCC
+	vf.Xres = xres
+	vf.Yres = yres
+	vf.LineStride = xres * bytesPerPixel
+	vf.Data = &data[0]
+	return vf, data
+}
+
+func TestScaleVideoFrame(t *testing.T) {
+	//A thin vertical line every 4 pixels, which point sampling misses completely.
+	src, _ := newTestVideoFrame(FourCCTypeBGRA, 8, 8, 4, func(x, y int) byte {
+		if x%4 == 0 {
+			return 255
+		}
+		return 0
+	})
+
+	tests := []struct {
+		filter   ScaleFilter
+		expected byte
+	}{
+		{ScaleFilterArea, 64},
+		{ScaleFilterBilinear, 0},
+	}
+
+	for _, tt := range tests {
+		dst, err := ScaleVideoFrame(src, 2, 2, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dst.Xres != 2 || dst.Yres != 2 || dst.LineStride != 8 {
+			t.Fatalf("Unexpected frame geometry %dx%d stride %d.", dst.Xres, dst.Yres, dst.LineStride)
+		}
+
+		for i, v := range dst.data() {
+			if v != tt.expected {
+				t.Errorf("Filter %d: byte %d is %d, expected %d.", tt.filter, i, v, tt.expected)
+			}
+		}
+	}
+}
+
+func TestDownscaleVideoFrame(t *testing.T) {
+	src, _ := newTestVideoFrame(FourCCTypeUYVY, 8, 4, 2, func(x, y int) byte { return byte(y * 10) })
+
+	dst, err := DownscaleVideoFrame(src, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range dst.data() {
+		if expected := byte(i/8*20 + 5); v != expected {
+			t.Errorf("Byte %d is %d, expected %d.", i, v, expected)
+		}
+	}
+
+	if _, err := DownscaleVideoFrame(src, 16, 4); err != invalidResolutionErr {
+		t.Errorf("Expected %v when upscaling but got %v.", invalidResolutionErr, err)
+	}
+	if _, err := DownscaleVideoFrame(src, 3, 2); err != invalidResolutionErr {
+		t.Errorf("Expected %v for an odd UYVY width but got %v.", invalidResolutionErr, err)
+	}
+}
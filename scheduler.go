@@ -0,0 +1,191 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var schedulerClosedErr = errors.New("send scheduler is closed")
+
+//SendScheduler runs the per-frame work (transform, conversion, send) of many senders in one process on a shared
+//pool of workers. Senders are served round-robin, one frame at a time, so that a busy sender cannot starve the others.
+type SendScheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	senders []*ScheduledSender
+	next    int
+	closed  bool
+	wg      sync.WaitGroup
+	clock   clock
+}
+
+//ScheduledSender is the handle a sender uses to queue work on a SendScheduler.
+type ScheduledSender struct {
+	Name string
+
+	sched *SendScheduler
+	jobs  []scheduledJob
+
+	//Guarded by sched.mu.
+	completed, missed, consecutiveMisses int
+}
+
+type scheduledJob struct {
+	deadline time.Time
+	work     func()
+}
+
+//SendSchedulerStats describes how well a sender kept up with its deadlines.
+type SendSchedulerStats struct {
+	Name                                 string
+	Completed, Missed, ConsecutiveMisses int
+}
+
+//NewSendScheduler starts a scheduler with the given number of workers.
+func NewSendScheduler(workers int) *SendScheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	s := &SendScheduler{clock: sysClock}
+	s.cond = sync.NewCond(&s.mu)
+	s.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+//Register adds a sender to the round-robin rotation.
+func (s *SendScheduler) Register(name string) *ScheduledSender {
+	ss := &ScheduledSender{Name: name, sched: s}
+
+	s.mu.Lock()
+	s.senders = append(s.senders, ss)
+	s.mu.Unlock()
+	return ss
+}
+
+//Unregister removes the sender from the rotation. Work that has not started yet is dropped.
+func (ss *ScheduledSender) Unregister() {
+	s := ss.sched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, o := range s.senders {
+		if o == ss {
+			s.senders = append(s.senders[:i], s.senders[i+1:]...)
+			if s.next > i {
+				s.next--
+			}
+			break
+		}
+	}
+	ss.jobs = nil
+}
+
+//Submit queues the work for one frame. A frame whose work finishes after deadline counts as a missed slot,
+//a zero deadline never misses.
+func (ss *ScheduledSender) Submit(deadline time.Time, work func()) error {
+	s := ss.sched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return schedulerClosedErr
+	}
+
+	ss.jobs = append(ss.jobs, scheduledJob{deadline, work})
+	s.cond.Signal()
+	return nil
+}
+
+//Stats returns the deadline statistics of every registered sender.
+func (s *SendScheduler) Stats() []SendSchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]SendSchedulerStats, len(s.senders))
+	for i, ss := range s.senders {
+		stats[i] = SendSchedulerStats{ss.Name, ss.completed, ss.missed, ss.consecutiveMisses}
+	}
+	return stats
+}
+
+//Laggards returns the names of the senders that missed at least n consecutive slots.
+func (s *SendScheduler) Laggards(n int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for _, ss := range s.senders {
+		if ss.consecutiveMisses >= n {
+			names = append(names, ss.Name)
+		}
+	}
+	return names
+}
+
+//Close stops accepting work, waits for the queued work to finish and stops the workers.
+func (s *SendScheduler) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+//Picks the next job in round-robin order. Must be called with s.mu held.
+func (s *SendScheduler) nextJob() (*ScheduledSender, scheduledJob, bool) {
+	for i := 0; i < len(s.senders); i++ {
+		idx := (s.next + i) % len(s.senders)
+		ss := s.senders[idx]
+		if len(ss.jobs) == 0 {
+			continue
+		}
+
+		job := ss.jobs[0]
+		ss.jobs[0] = scheduledJob{}
+		ss.jobs = ss.jobs[1:]
+		s.next = (idx + 1) % len(s.senders)
+		return ss, job, true
+	}
+	return nil, scheduledJob{}, false
+}
+
+func (s *SendScheduler) worker() {
+	defer s.wg.Done()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		ss, job, ok := s.nextJob()
+		if !ok {
+			if s.closed {
+				return
+			}
+			s.cond.Wait()
+			continue
+		}
+
+		s.mu.Unlock()
+		job.work()
+		missed := !job.deadline.IsZero() && s.clock.Now().After(job.deadline)
+		s.mu.Lock()
+
+		ss.completed++
+		if missed {
+			ss.missed++
+			ss.consecutiveMisses++
+		} else {
+			ss.consecutiveMisses = 0
+		}
+	}
+}
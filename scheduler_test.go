@@ -0,0 +1,80 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendSchedulerFairness(t *testing.T) {
+	const (
+		numBusy  = 500
+		numLight = 20
+	)
+
+	sched := NewSendScheduler(1)
+	defer sched.Close()
+
+	busy := sched.Register("busy")
+	light := sched.Register("light")
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+
+	//Hold the only worker until the imbalance has been queued.
+	gate := make(chan struct{})
+	busy.Submit(time.Time{}, func() { <-gate })
+	for i := 0; i < numBusy; i++ {
+		busy.Submit(time.Time{}, record("busy"))
+	}
+	done := make(chan int, 1)
+	for i := 0; i < numLight-1; i++ {
+		light.Submit(time.Time{}, record("light"))
+	}
+	light.Submit(time.Time{}, func() {
+		record("light")()
+		mu.Lock()
+		done <- len(order)
+		mu.Unlock()
+	})
+	close(gate)
+
+	if n := <-done; n > 2*numLight {
+		t.Errorf("Light sender finished after %d frames, expected at most %d.", n, 2*numLight)
+	}
+}
+
+func TestSendSchedulerLaggards(t *testing.T) {
+	clock := useFakeClock(t)
+	sched := NewSendScheduler(2)
+
+	slow := sched.Register("slow")
+	fast := sched.Register("fast")
+	for i := 0; i < 5; i++ {
+		slow.Submit(clock.Now(), func() { clock.Advance(time.Millisecond) })
+		fast.Submit(clock.Now().Add(time.Hour), func() {})
+	}
+	sched.Close()
+
+	laggards := sched.Laggards(5)
+	if len(laggards) != 1 || laggards[0] != "slow" {
+		t.Errorf("Expected only the slow sender to lag but got %v.", laggards)
+	}
+
+	if err := fast.Submit(time.Time{}, func() {}); err != schedulerClosedErr {
+		t.Errorf("Expected %v but got %v.", schedulerClosedErr, err)
+	}
+}
@@ -5,6 +5,11 @@
 package ndi
 
 import (
+	"encoding/xml"
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"sync"
 	"syscall"
 	"unsafe"
 )
@@ -20,6 +25,12 @@ func NewSendInstance(settings *SendCreateSettings) *SendInstance {
 }
 
 func (inst *SendInstance) Destroy() {
+	inst.releaseAsync()
+
+	failoverSourcesMu.Lock()
+	delete(failoverSources, inst)
+	failoverSourcesMu.Unlock()
+
 	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendDestroy, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
 		panic(eno)
 	}
@@ -27,11 +38,161 @@ func (inst *SendInstance) Destroy() {
 
 //This will add a video frame.
 func (inst *SendInstance) SendVideoV2(frame *VideoFrameV2) {
+	inst.releaseAsync()
+
 	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendSendVideoV2, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
 		panic(eno)
 	}
 }
 
+//Like SendVideoV2, but returns an error without sending if the frame does not fit into its external buffer.
+func (inst *SendInstance) SendVideoV2Checked(frame *ExternalVideoFrame) error {
+	if err := frame.CheckSize(); err != nil {
+		return err
+	}
+	inst.SendVideoV2(&frame.VideoFrameV2)
+	return nil
+}
+
+//This will add an audio frame. Returns an error without sending if the frame's data does not match its size.
+func (inst *SendInstance) SendAudioV2(frame *AudioFrameV2) error {
+	if frame == nil || frame.NumSamples < 0 || frame.NumChannels < 0 {
+		return invalidAudioFrameErr
+	}
+	if frame.NumSamples > 0 && frame.NumChannels > 0 && (frame.Data == nil || frame.ChannelStride < frame.NumSamples*4) {
+		return invalidAudioFrameErr
+	}
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendSendAudioV2, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
+		panic(eno)
+	}
+	return nil
+}
+
+var audioV3UnsupportedErr = errors.New("the loaded NDI runtime has no send_send_audio_v3, it needs version 4.0 or later")
+
+//This will add an audio frame in the NDI 4 format. Uncompressed frames are checked like in SendAudioV2.
+//Returns an error if the runtime is older than 4.0, which lacks the call.
+func (inst *SendInstance) SendAudioV3(frame *AudioFrameV3) error {
+	if funcPtrs.NDIlibSendSendAudioV3 == 0 {
+		return audioV3UnsupportedErr
+	}
+	if frame == nil || frame.NumSamples < 0 || frame.NumChannels < 0 {
+		return invalidAudioFrameErr
+	}
+	if frame.FourCC == FourCCAudioTypeFLTP && frame.NumSamples > 0 && frame.NumChannels > 0 && (frame.Data == nil || frame.ChannelStride < frame.NumSamples*4) {
+		return invalidAudioFrameErr
+	}
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendSendAudioV3, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
+		panic(eno)
+	}
+	return nil
+}
+
+//Sends interleaved 16-bit audio, converted by the SDK without an extra copy on the Go side. Returns an error
+//without sending if the frame has samples but no data.
+func (inst *SendInstance) SendAudioInterleaved16s(frame *AudioFrameInterleaved16s) error {
+	if frame == nil {
+		return invalidAudioFrameErr
+	}
+	if err := checkInterleavedAudio(frame.NumChannels, frame.NumSamples, frame.Data != nil); err != nil {
+		return err
+	}
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibUtilSendSendAudioInterleaved16s, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
+		panic(eno)
+	}
+	return nil
+}
+
+//Like SendAudioInterleaved16s, but for 32-bit audio.
+func (inst *SendInstance) SendAudioInterleaved32s(frame *AudioFrameInterleaved32s) error {
+	if frame == nil {
+		return invalidAudioFrameErr
+	}
+	if err := checkInterleavedAudio(frame.NumChannels, frame.NumSamples, frame.Data != nil); err != nil {
+		return err
+	}
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibUtilSendSendAudioInterleaved32s, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
+		panic(eno)
+	}
+	return nil
+}
+
+//Like SendAudioInterleaved16s, but for floating point audio.
+func (inst *SendInstance) SendAudioInterleaved32f(frame *AudioFrameInterleaved32f) error {
+	if frame == nil {
+		return invalidAudioFrameErr
+	}
+	if err := checkInterleavedAudio(frame.NumChannels, frame.NumSamples, frame.Data != nil); err != nil {
+		return err
+	}
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibUtilSendSendAudioInterleaved32f, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
+		panic(eno)
+	}
+	return nil
+}
+
+var asyncBufferInUseErr = errors.New("buffer is still in use by the previous asynchronous send")
+
+//The data of the last frame each sender was given by SendVideoAsyncV2, which the SDK reads until the next send.
+//Holding it here also keeps a Go allocated buffer from being collected while the SDK still reads it. SendInstance is
+//an SDK handle with no room for it. The mutex only guards the map, as the send itself may block until the SDK
+//releases the previous frame.
+var (
+	pendingAsyncMu sync.Mutex
+	pendingAsync   = make(map[*SendInstance]*byte)
+)
+
+//This will add a video frame and will return immediately, having scheduled the frame to be displayed. The SDK keeps
+//reading the frame's data until the next call to SendVideoAsyncV2 or SendVideoV2, so consecutive frames must use
+//different buffers, otherwise an error is returned. Pass nil to wait until the last frame has been released.
+func (inst *SendInstance) SendVideoAsyncV2(frame *VideoFrameV2) error {
+	pendingAsyncMu.Lock()
+	pending := pendingAsync[inst]
+	pendingAsyncMu.Unlock()
+
+	if frame != nil && frame.Data != nil && frame.Data == pending {
+		return asyncBufferInUseErr
+	}
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendSendVideoAsyncV2, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(frame)), 0); eno != 0 {
+		panic(eno)
+	}
+
+	pendingAsyncMu.Lock()
+	if frame == nil || frame.Data == nil {
+		delete(pendingAsync, inst)
+	} else {
+		pendingAsync[inst] = frame.Data
+	}
+	pendingAsyncMu.Unlock()
+	return nil
+}
+
+//Like SendVideoAsyncV2, but returns an error without sending if the frame does not fit into its external buffer.
+func (inst *SendInstance) SendVideoAsyncV2Checked(frame *ExternalVideoFrame) error {
+	if err := frame.CheckSize(); err != nil {
+		return err
+	}
+	return inst.SendVideoAsyncV2(&frame.VideoFrameV2)
+}
+
+//Waits until the SDK has released the frame of the last SendVideoAsyncV2 call, after which its buffer may be reused.
+func (inst *SendInstance) Flush() error {
+	return inst.SendVideoAsyncV2(nil)
+}
+
+//Forgets the last asynchronous frame, which the SDK releases on any other send and on destroy.
+func (inst *SendInstance) releaseAsync() {
+	pendingAsyncMu.Lock()
+	delete(pendingAsync, inst)
+	pendingAsyncMu.Unlock()
+}
+
 //Get the current number of receivers connected to this source. This can be used to avoid even rendering when nothing is connected to the video source.
 //which can significantly improve the efficiency if you want to make a lot of sources available on the network. If you specify a timeout that is not
 //0 then it will wait until there are connections for this amount of time.
@@ -42,3 +203,204 @@ func (inst *SendInstance) GetNumConnections(timeoutInMs uint32) (int, error) {
 	}
 	return int(ret), nil
 }
+
+//Like GetNumConnections, but returns zero if the connections cannot be queried. While nothing is connected it blocks
+//until the first receiver connects or timeoutInMs passes, a zero timeout returns the current count right away.
+func (inst *SendInstance) GetNoConnections(timeoutInMs uint32) int {
+	n, err := inst.GetNumConnections(timeoutInMs)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+//Reports whether any receiver is connected, waiting up to timeoutInMs for one. Senders can use it to skip rendering
+//and encoding while nobody is watching.
+func (inst *SendInstance) HasConnections(timeoutInMs uint32) bool {
+	return inst.GetNoConnections(timeoutInMs) > 0
+}
+
+var sourceNameUnsupportedErr = errors.New("the loaded NDI runtime has no send_get_source_name, it needs version 3.8 or later")
+
+//Returns the source receivers use to connect to this sender, with the full name including the machine name and the
+//address. The source is copied out of SDK memory. Returns an error if the runtime is older than 3.8, which lacks the
+//call. Safe to call from multiple goroutines.
+func (inst *SendInstance) SourceName() (Source, error) {
+	if funcPtrs.NDIlibSourceTv38 == 0 {
+		return Source{}, sourceNameUnsupportedErr
+	}
+
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibSourceTv38, 1, uintptr(unsafe.Pointer(inst)), 0, 0)
+	if eno != 0 {
+		return Source{}, Error{eno}
+	}
+	return copySource(ret), nil
+}
+
+//Like SourceName, but returns the zero Source on failure.
+func (inst *SendInstance) GetSourceName() Source {
+	source, _ := inst.SourceName()
+	return source
+}
+
+//Get the tally state of this source, i.e. whether a receiver has it on program or preview. The bool reports whether
+//the tally changed, it is false without an error if timeoutInMs passes without a change.
+func (inst *SendInstance) GetTally(timeoutInMs uint32) (Tally, bool, error) {
+	var tally Tally
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibSendGetTally, 3, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(&tally)), uintptr(timeoutInMs))
+	if eno != 0 {
+		return Tally{}, false, Error{eno}
+	}
+	return tally, byte(ret) != 0, nil
+}
+
+//Sends a metadata frame to every receiver that is connected to this source.
+func (inst *SendInstance) SendMetadata(mf *MetadataFrame) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendSendMetadata, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Receives metadata that the connected receivers send back, like PTZ or KVM commands. Returns FrameTypeMetadata with
+//the frame in mf, which must be freed with FreeMetadata, or FrameTypeNone without an error if timeoutInMs passes
+//first. Like RecvInstance.Capture it refuses to overwrite a frame that has not been freed.
+func (inst *SendInstance) Capture(mf *MetadataFrame, timeoutInMs uint32) (FrameType, error) {
+	if mf.Data != nil {
+		return FrameTypeNone, frameStillOwnedErr
+	}
+
+	ret, _, eno := syscall.Syscall(funcPtrs.NDIlibSendCapture, 3, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), uintptr(timeoutInMs))
+	if eno != 0 {
+		return FrameTypeNone, Error{eno}
+	}
+
+	ft := FrameType(ret)
+	if ft == FrameTypeError {
+		return ft, connectionLostErr
+	}
+	return ft, nil
+}
+
+//Like Capture but allocates the frame. Returns nil without an error when no metadata arrived within timeoutInMs.
+//The returned frame must be freed with FreeMetadata.
+func (inst *SendInstance) CaptureMetadata(timeoutInMs uint32) (*MetadataFrame, error) {
+	mf := NewMetadataFrame()
+	ft, err := inst.Capture(mf, timeoutInMs)
+	if err != nil || ft != FrameTypeMetadata {
+		return nil, err
+	}
+	return mf, nil
+}
+
+//Frees a metadata frame returned by Capture. Frames without data are ignored and the frame is reset afterwards, so
+//it can be passed to Capture again.
+func (inst *SendInstance) FreeMetadata(mf *MetadataFrame) {
+	if mf == nil || mf.Data == nil {
+		return
+	}
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendFreeMetadata, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), 0); eno != 0 {
+		panic(eno)
+	}
+	mf.Data = nil
+}
+
+//Copies of the sources given to SetFailover, kept for as long as the sender may refer to them.
+var (
+	failoverSourcesMu sync.Mutex
+	failoverSources   = make(map[*SendInstance]*Source)
+)
+
+//Sets the source that receivers of this sender switch to when it goes away, for example a backup machine running the
+//same graphics. The name and address are copied, so src may be changed afterwards. Passing nil clears the failover.
+func (inst *SendInstance) SetFailover(src *Source) {
+	var failover *Source
+	if src != nil {
+		s := NewSource(src.Name(), src.Address())
+		failover = &s
+	}
+
+	failoverSourcesMu.Lock()
+	defer failoverSourcesMu.Unlock()
+
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendSetFailover, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(failover)), 0); eno != 0 {
+		panic(eno)
+	}
+
+	if failover == nil {
+		delete(failoverSources, inst)
+	} else {
+		failoverSources[inst] = failover
+	}
+}
+
+//Removes the failover source set with SetFailover, same as SetFailover(nil).
+func (inst *SendInstance) ClearFailover() {
+	inst.SetFailover(nil)
+}
+
+//Add to the list of connection metadata that is sent to every receiver that connects to this source, e.g. product
+//names or capabilities. The metadata is copied, so mf can be reused afterwards.
+//
+//The SDK does not synchronize the connection metadata with sending. This must not be called while another goroutine
+//is inside SendVideoV2, SendAudioV2 or any other call on inst, and no locking is done here to prevent that.
+func (inst *SendInstance) AddConnectionMetadata(mf *MetadataFrame) {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendAddConnectionMetadata, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Removes all connection metadata added with AddConnectionMetadata. Safe to call when none was added.
+//The same threading restriction as for AddConnectionMetadata applies.
+func (inst *SendInstance) ClearConnectionMetadata() {
+	if _, _, eno := syscall.Syscall(funcPtrs.NDIlibSendClearConnectionMetadata, 1, uintptr(unsafe.Pointer(inst)), 0, 0); eno != 0 {
+		panic(eno)
+	}
+}
+
+//Same as AddConnectionMetadata but takes the metadata as an XML string, with the same threading restriction.
+func (inst *SendInstance) AddConnectionMetadataXML(metadata string) error {
+	data := make([]byte, len(metadata)+1)
+	copy(data, metadata)
+
+	mf := NewMetadataFrame()
+	mf.Data = &data[0]
+
+	_, _, eno := syscall.Syscall(funcPtrs.NDIlibSendAddConnectionMetadata, 2, uintptr(unsafe.Pointer(inst)), uintptr(unsafe.Pointer(mf)), 0)
+	runtime.KeepAlive(data)
+	if eno != 0 {
+		return Error{eno}
+	}
+	return nil
+}
+
+type goVersionMetadata struct {
+	XMLName       xml.Name `xml:"ndi_go"`
+	GoVersion     string   `xml:"go_version,attr"`
+	GOOS          string   `xml:"goos,attr"`
+	GOARCH        string   `xml:"goarch,attr"`
+	Path          string   `xml:"path,attr,omitempty"`
+	Module        string   `xml:"module,attr,omitempty"`
+	ModuleVersion string   `xml:"module_version,attr,omitempty"`
+}
+
+//Adds the Go runtime version and the build info of the running binary to the connection metadata, which makes
+//Go based senders easy to identify in the connection logs of receivers.
+func (inst *SendInstance) AnnounceGoVersion() error {
+	md := goVersionMetadata{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		md.Path = info.Path
+		md.Module = info.Main.Path
+		md.ModuleVersion = info.Main.Version
+	}
+
+	b, err := xml.Marshal(md)
+	if err != nil {
+		return err
+	}
+	return inst.AddConnectionMetadataXML(string(b))
+}
@@ -0,0 +1,51 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+//Replaces the asynchronous video send of the SDK with a callback counting the frames, restoring it when t ends.
+func fakeAsyncSend(t *testing.T) *int {
+	saved := funcPtrs
+	t.Cleanup(func() { funcPtrs = saved })
+
+	var sent int
+	funcPtrs = &ndiLIBv5{NDIlibSendSendVideoAsyncV2: syscall.NewCallback(func(inst, frame uintptr) uintptr {
+		sent++
+		return 0
+	})}
+	return &sent
+}
+
+func TestSendVideoAsyncV2Checked(t *testing.T) {
+	sent := fakeAsyncSend(t)
+	inst := new(SendInstance)
+	defer inst.releaseAsync()
+
+	buf := make([]byte, 4*4*2)
+	vf := NewExternalVideoFrame()
+	vf.FourCC, vf.Xres, vf.Yres, vf.LineStride = FourCCTypeBGRA, 4, 2, 16
+	vf.SetExternalData(unsafe.Pointer(&buf[0]), len(buf))
+
+	if err := inst.SendVideoAsyncV2Checked(vf); err != nil || *sent != 1 {
+		t.Fatalf("Expected the frame to be sent but got %v after %d sends.", err, *sent)
+	}
+	if err := inst.SendVideoAsyncV2Checked(vf); err != asyncBufferInUseErr {
+		t.Errorf("Expected %v but got %v.", asyncBufferInUseErr, err)
+	}
+
+	//The size is checked before the buffer is compared with the pending one.
+	vf.Yres = 3
+	if err := inst.SendVideoAsyncV2Checked(vf); err != externalDataTooSmallErr {
+		t.Errorf("Expected %v but got %v.", externalDataTooSmallErr, err)
+	}
+	if *sent != 1 {
+		t.Errorf("Expected 1 send but got %d.", *sent)
+	}
+}
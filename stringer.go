@@ -0,0 +1,75 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "strconv"
+
+//String returns the name of the constant. Like the other String methods in this file, it prints unknown values
+//the way Go prints a conversion, e.g. "FrameType(7)".
+func (t FrameType) String() string {
+	switch t {
+	case FrameTypeNone:
+		return "FrameTypeNone"
+	case FrameTypeVideo:
+		return "FrameTypeVideo"
+	case FrameTypeAudio:
+		return "FrameTypeAudio"
+	case FrameTypeMetadata:
+		return "FrameTypeMetadata"
+	case FrameTypeError:
+		return "FrameTypeError"
+	case FrameTypeStatusChange:
+		return "FrameTypeStatusChange"
+	}
+	return "FrameType(" + strconv.Itoa(int(t)) + ")"
+}
+
+func (f FrameFormat) String() string {
+	switch f {
+	case FrameFormatInterleaved:
+		return "FrameFormatInterleaved"
+	case FrameFormatProgressive:
+		return "FrameFormatProgressive"
+	case FrameFormatField0:
+		return "FrameFormatField0"
+	case FrameFormatField1:
+		return "FrameFormatField1"
+	}
+	return "FrameFormat(" + strconv.Itoa(int(f)) + ")"
+}
+
+func (b RecvBandwidth) String() string {
+	switch b {
+	case RecvBandwidthMetadataOnly:
+		return "RecvBandwidthMetadataOnly"
+	case RecvBandwidthAudioOnly:
+		return "RecvBandwidthAudioOnly"
+	case RecvBandwidthLowest:
+		return "RecvBandwidthLowest"
+	case RecvBandwidthHighest:
+		return "RecvBandwidthHighest"
+	}
+	return "RecvBandwidth(" + strconv.Itoa(int(b)) + ")"
+}
+
+func (f RecvColorFormat) String() string {
+	switch f {
+	case RecvColorFormatBGRXBGRA:
+		return "RecvColorFormatBGRXBGRA"
+	case RecvColorFormatUYVYBGRA:
+		return "RecvColorFormatUYVYBGRA"
+	case RecvColorFormatRGBXRGBA:
+		return "RecvColorFormatRGBXRGBA"
+	case RecvColorFormatUYVYRGBA:
+		return "RecvColorFormatUYVYRGBA"
+	case RecvColorFormatFastest:
+		return "RecvColorFormatFastest"
+	case RecvColorFormatBest:
+		return "RecvColorFormatBest"
+	case RecvColorFormatBGRXBGRAFlipped:
+		return "RecvColorFormatBGRXBGRAFlipped"
+	}
+	return "RecvColorFormat(" + strconv.Itoa(int(f)) + ")"
+}
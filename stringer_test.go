@@ -0,0 +1,67 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+//Checks that every value has its own name that is not a number, and that unknown is printed as a conversion.
+func checkStringer(t *testing.T, values []fmt.Stringer, unknown fmt.Stringer, unknownString string) {
+	seen := make(map[string]bool)
+	for _, v := range values {
+		s := v.String()
+		if _, err := strconv.Atoi(s); s == "" || err == nil || seen[s] {
+			t.Errorf("Expected a unique name but got %q.", s)
+		}
+		seen[s] = true
+	}
+	if s := unknown.String(); s != unknownString {
+		t.Errorf("Expected %s but got %s.", unknownString, s)
+	}
+}
+
+func TestFrameTypeString(t *testing.T) {
+	checkStringer(t, []fmt.Stringer{
+		FrameTypeNone, FrameTypeVideo, FrameTypeAudio, FrameTypeMetadata, FrameTypeError, FrameTypeStatusChange,
+	}, FrameType(99), "FrameType(99)")
+
+	if s := fmt.Sprintf("received frame type %v", FrameTypeAudio); s != "received frame type FrameTypeAudio" {
+		t.Errorf("Unexpected log line %q.", s)
+	}
+}
+
+func TestFrameFormatString(t *testing.T) {
+	checkStringer(t, []fmt.Stringer{
+		FrameFormatInterleaved, FrameFormatProgressive, FrameFormatField0, FrameFormatField1,
+	}, FrameFormat(-1), "FrameFormat(-1)")
+}
+
+func TestRecvBandwidthString(t *testing.T) {
+	checkStringer(t, []fmt.Stringer{
+		RecvBandwidthMetadataOnly, RecvBandwidthAudioOnly, RecvBandwidthLowest, RecvBandwidthHighest,
+	}, RecvBandwidth(50), "RecvBandwidth(50)")
+}
+
+func TestRecvColorFormatString(t *testing.T) {
+	formats := []RecvColorFormat{
+		RecvColorFormatBGRXBGRA, RecvColorFormatUYVYBGRA, RecvColorFormatRGBXRGBA, RecvColorFormatUYVYRGBA,
+		RecvColorFormatFastest, RecvColorFormatBest, RecvColorFormatBGRXBGRAFlipped,
+	}
+	values := make([]fmt.Stringer, len(formats))
+	for i, f := range formats {
+		if !IsValidRecvColorFormat(f) {
+			t.Errorf("Expected %s to be valid.", f)
+		}
+		values[i] = f
+	}
+	checkStringer(t, values, RecvColorFormat(7), "RecvColorFormat(7)")
+
+	if IsValidRecvColorFormat(7) {
+		t.Error("Expected 7 to be invalid.")
+	}
+}
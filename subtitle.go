@@ -0,0 +1,360 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+)
+
+var invalidSafeAreaErr = errors.New("safe area must be at least 0 and less than 0.5")
+
+type SubtitlePosition int
+
+const (
+	//Centered above the bottom margin of the safe area.
+	SubtitleBottom SubtitlePosition = iota
+
+	//Centered below the top margin of the safe area.
+	SubtitleTop
+
+	//Left aligned with the top left corner at X, Y of the options.
+	SubtitleCustom
+)
+
+//SubtitleFont provides the glyphs RenderSubtitle draws, so that fonts rendered with other packages, like
+//golang.org/x/image/font, can be used.
+type SubtitleFont interface {
+	//Returns the coverage of the glyph of r. Its width is the advance to the next glyph, its height is Height.
+	Glyph(r rune) *image.Alpha
+
+	Height() int
+}
+
+type SubtitleOptions struct {
+	//The font to draw with. Nil means a built-in 5x7 pixel font covering printable ASCII.
+	Font SubtitleFont
+
+	//The height of a line in pixels. The glyphs of the font are scaled by a whole factor to come close. Zero means
+	//an eighteenth of the frame height.
+	Size int
+
+	Position SubtitlePosition
+
+	//The top left corner of the text for SubtitleCustom, in pixels.
+	X, Y int
+
+	//Whether to draw a box behind every line, in BoxColor.
+	Box bool
+
+	//Zero means opaque white text and a black box at 60% opacity.
+	TextColor, BoxColor color.NRGBA
+
+	//The margin kept free on every side as a fraction of the frame size. Lines are wrapped at words to fit between
+	//the margins. Zero means 0.05.
+	SafeArea float32
+}
+
+const defaultSubtitleSafeArea = 0.05
+
+//RenderSubtitle burns text into vf in place. Lines are separated by newlines and wrapped to fit the safe area.
+//Text that does not fit into the frame is cut off. BGRA, BGRX and UYVY frames are supported.
+func RenderSubtitle(vf *VideoFrameV2, text string, opts SubtitleOptions) error {
+	c, err := newOverlayCanvas(vf)
+	if err != nil {
+		return err
+	}
+	width, height := int(vf.Xres), int(vf.Yres)
+
+	safeArea := opts.SafeArea
+	if safeArea == 0 {
+		safeArea = defaultSubtitleSafeArea
+	}
+	if safeArea < 0 || safeArea >= 0.5 {
+		return invalidSafeAreaErr
+	}
+
+	font := opts.Font
+	if font == nil {
+		font = basicSubtitleFont{}
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = height / 18
+	}
+	scale := maxInt(1, (size+font.Height()/2)/font.Height())
+
+	textColor, boxColor := opts.TextColor, opts.BoxColor
+	if textColor == (color.NRGBA{}) {
+		textColor = color.NRGBA{255, 255, 255, 255}
+	}
+	if boxColor == (color.NRGBA{}) {
+		boxColor = color.NRGBA{0, 0, 0, 153}
+	}
+
+	marginX, marginY := int(safeArea*float32(width)), int(safeArea*float32(height))
+	lines := wrapSubtitle(text, font, scale, width-2*marginX)
+	lineHeight := font.Height() * scale
+	pad := scale * 2
+
+	top := opts.Y
+	switch opts.Position {
+	case SubtitleBottom:
+		top = height - marginY - len(lines)*lineHeight - pad
+	case SubtitleTop:
+		top = marginY + pad
+	}
+
+	for i, line := range lines {
+		lineWidth := subtitleWidth(line, font, scale)
+		left := opts.X
+		if opts.Position != SubtitleCustom {
+			left = (width - lineWidth) / 2
+		}
+		y := top + i*lineHeight
+
+		if opts.Box {
+			for by := y - pad; by < y+lineHeight+pad; by++ {
+				for bx := left - pad; bx < left+lineWidth+pad; bx++ {
+					c.blend(bx, by, boxColor, 1)
+				}
+			}
+		}
+
+		x := left
+		for _, r := range line {
+			glyph := font.Glyph(r)
+			b := glyph.Bounds()
+			for gy := 0; gy < b.Dy()*scale; gy++ {
+				for gx := 0; gx < b.Dx()*scale; gx++ {
+					if a := glyph.AlphaAt(b.Min.X+gx/scale, b.Min.Y+gy/scale).A; a != 0 {
+						c.blend(x+gx, y+gy, textColor, float32(a)/255)
+					}
+				}
+			}
+			x += b.Dx() * scale
+		}
+	}
+	return nil
+}
+
+//Splits text into lines at newlines and wraps them at spaces to fit into maxWidth pixels. Words wider than
+//maxWidth get a line of their own.
+func wrapSubtitle(text string, font SubtitleFont, scale, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && subtitleWidth(line+" "+word, font, scale) > maxWidth {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func subtitleWidth(line string, font SubtitleFont, scale int) int {
+	var w int
+	for _, r := range line {
+		w += font.Glyph(r).Bounds().Dx() * scale
+	}
+	return w
+}
+
+//Draws overlays into a frame, ignoring pixels outside of it.
+type overlayCanvas struct {
+	vf            *VideoFrameV2
+	data          []byte
+	bytesPerPixel int
+}
+
+//Returns a canvas for vf, which must be BGRA, BGRX or UYVY.
+func newOverlayCanvas(vf *VideoFrameV2) (overlayCanvas, error) {
+	if vf == nil || vf.Xres <= 0 || vf.Yres <= 0 {
+		return overlayCanvas{}, invalidVideoFrameErr
+	}
+	var bytesPerPixel int
+	switch vf.FourCC {
+	case FourCCTypeBGRA, FourCCTypeBGRX:
+		bytesPerPixel = 4
+	case FourCCTypeUYVY:
+		bytesPerPixel = 2
+	default:
+		return overlayCanvas{}, unsupportedFourCCErr
+	}
+
+	width := int(vf.Xres)
+	data := vf.data()
+	if data == nil || int(vf.LineStride) < width*bytesPerPixel || vf.FourCC == FourCCTypeUYVY && width%2 != 0 {
+		return overlayCanvas{}, invalidVideoFrameErr
+	}
+	return overlayCanvas{vf, data, bytesPerPixel}, nil
+}
+
+//Blends c over the pixel at x, y with its alpha scaled by coverage.
+func (s overlayCanvas) blend(x, y int, c color.NRGBA, coverage float32) {
+	if x < 0 || y < 0 || x >= int(s.vf.Xres) || y >= int(s.vf.Yres) {
+		return
+	}
+	a := coverage * float32(c.A) / 255
+	mix := func(dst *byte, v, alpha float32) {
+		*dst = clampByte(v*alpha + float32(*dst)*(1-alpha))
+	}
+
+	row := s.data[y*int(s.vf.LineStride):]
+	if s.bytesPerPixel == 4 {
+		px := row[x*4 : x*4+4]
+		mix(&px[0], float32(c.B), a)
+		mix(&px[1], float32(c.G), a)
+		mix(&px[2], float32(c.R), a)
+		mix(&px[3], 255, a)
+		return
+	}
+
+	//BT.709 limited range, chroma is shared by two pixels so each contributes half.
+	r, g, b := float32(c.R), float32(c.G), float32(c.B)
+	luma := 16 + (0.2126*r+0.7152*g+0.0722*b)*219/255
+	cb := 128 + (-0.1146*r-0.3854*g+0.5*b)*224/255
+	cr := 128 + (0.5*r-0.4542*g-0.0458*b)*224/255
+
+	pair := row[x/2*4 : x/2*4+4]
+	mix(&pair[1+x%2*2], luma, a)
+	mix(&pair[0], cb, a/2)
+	mix(&pair[2], cr, a/2)
+}
+
+//The built-in font, 5x7 pixel glyphs in 6x8 cells.
+type basicSubtitleFont struct{}
+
+func (basicSubtitleFont) Height() int { return 8 }
+
+func (basicSubtitleFont) Glyph(r rune) *image.Alpha {
+	if r < ' ' || r > '~' {
+		r = '?'
+	}
+	return basicSubtitleMasks[r-' ']
+}
+
+var basicSubtitleMasks = func() (masks [95]*image.Alpha) {
+	for i, rows := range basicSubtitleGlyphs {
+		masks[i] = image.NewAlpha(image.Rect(0, 0, 6, 8))
+		for y, bits := range rows {
+			for x := 0; x < 5; x++ {
+				if bits&(0x10>>x) != 0 {
+					masks[i].Pix[y*masks[i].Stride+x] = 255
+				}
+			}
+		}
+	}
+	return
+}()
+
+//The rows of the printable ASCII glyphs, the highest of the five bits is the leftmost pixel.
+var basicSubtitleGlyphs = [95][7]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, //' '
+	{0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04}, //'!'
+	{0x0a, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00}, //'"'
+	{0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a}, //'#'
+	{0x04, 0x0f, 0x14, 0x0e, 0x05, 0x1e, 0x04}, //'$'
+	{0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03}, //'%'
+	{0x0c, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0d}, //'&'
+	{0x04, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00}, //'\''
+	{0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02}, //'('
+	{0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08}, //')'
+	{0x00, 0x04, 0x15, 0x0e, 0x15, 0x04, 0x00}, //'*'
+	{0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00}, //'+'
+	{0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08}, //','
+	{0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00}, //'-'
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c}, //'.'
+	{0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00}, //'/'
+	{0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e}, //'0'
+	{0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e}, //'1'
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f}, //'2'
+	{0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e}, //'3'
+	{0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02}, //'4'
+	{0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e}, //'5'
+	{0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e}, //'6'
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08}, //'7'
+	{0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e}, //'8'
+	{0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c}, //'9'
+	{0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00}, //':'
+	{0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x04, 0x08}, //';'
+	{0x02, 0x04, 0x08, 0x10, 0x08, 0x04, 0x02}, //'<'
+	{0x00, 0x00, 0x1f, 0x00, 0x1f, 0x00, 0x00}, //'='
+	{0x08, 0x04, 0x02, 0x01, 0x02, 0x04, 0x08}, //'>'
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04}, //'?'
+	{0x0e, 0x11, 0x01, 0x0d, 0x15, 0x15, 0x0e}, //'@'
+	{0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}, //'A'
+	{0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e}, //'B'
+	{0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e}, //'C'
+	{0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c}, //'D'
+	{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f}, //'E'
+	{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10}, //'F'
+	{0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f}, //'G'
+	{0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}, //'H'
+	{0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}, //'I'
+	{0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c}, //'J'
+	{0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11}, //'K'
+	{0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f}, //'L'
+	{0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11}, //'M'
+	{0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11}, //'N'
+	{0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}, //'O'
+	{0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10}, //'P'
+	{0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d}, //'Q'
+	{0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11}, //'R'
+	{0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e}, //'S'
+	{0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, //'T'
+	{0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}, //'U'
+	{0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04}, //'V'
+	{0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a}, //'W'
+	{0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11}, //'X'
+	{0x11, 0x11, 0x11, 0x0a, 0x04, 0x04, 0x04}, //'Y'
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f}, //'Z'
+	{0x0e, 0x08, 0x08, 0x08, 0x08, 0x08, 0x0e}, //'['
+	{0x00, 0x10, 0x08, 0x04, 0x02, 0x01, 0x00}, //'\\'
+	{0x0e, 0x02, 0x02, 0x02, 0x02, 0x02, 0x0e}, //']'
+	{0x04, 0x0a, 0x11, 0x00, 0x00, 0x00, 0x00}, //'^'
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f}, //'_'
+	{0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00}, //'`'
+	{0x00, 0x00, 0x0e, 0x01, 0x0f, 0x11, 0x0f}, //'a'
+	{0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x1e}, //'b'
+	{0x00, 0x00, 0x0e, 0x10, 0x10, 0x11, 0x0e}, //'c'
+	{0x01, 0x01, 0x0d, 0x13, 0x11, 0x11, 0x0f}, //'d'
+	{0x00, 0x00, 0x0e, 0x11, 0x1f, 0x10, 0x0e}, //'e'
+	{0x06, 0x09, 0x08, 0x1c, 0x08, 0x08, 0x08}, //'f'
+	{0x00, 0x0f, 0x11, 0x11, 0x0f, 0x01, 0x0e}, //'g'
+	{0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x11}, //'h'
+	{0x04, 0x00, 0x0c, 0x04, 0x04, 0x04, 0x0e}, //'i'
+	{0x02, 0x00, 0x06, 0x02, 0x02, 0x12, 0x0c}, //'j'
+	{0x10, 0x10, 0x12, 0x14, 0x18, 0x14, 0x12}, //'k'
+	{0x0c, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}, //'l'
+	{0x00, 0x00, 0x1a, 0x15, 0x15, 0x11, 0x11}, //'m'
+	{0x00, 0x00, 0x16, 0x19, 0x11, 0x11, 0x11}, //'n'
+	{0x00, 0x00, 0x0e, 0x11, 0x11, 0x11, 0x0e}, //'o'
+	{0x00, 0x00, 0x1e, 0x11, 0x1e, 0x10, 0x10}, //'p'
+	{0x00, 0x00, 0x0d, 0x13, 0x0f, 0x01, 0x01}, //'q'
+	{0x00, 0x00, 0x16, 0x19, 0x10, 0x10, 0x10}, //'r'
+	{0x00, 0x00, 0x0f, 0x10, 0x0e, 0x01, 0x1e}, //'s'
+	{0x08, 0x08, 0x1c, 0x08, 0x08, 0x09, 0x06}, //'t'
+	{0x00, 0x00, 0x11, 0x11, 0x11, 0x13, 0x0d}, //'u'
+	{0x00, 0x00, 0x11, 0x11, 0x11, 0x0a, 0x04}, //'v'
+	{0x00, 0x00, 0x11, 0x11, 0x15, 0x15, 0x0a}, //'w'
+	{0x00, 0x00, 0x11, 0x0a, 0x04, 0x0a, 0x11}, //'x'
+	{0x00, 0x00, 0x11, 0x11, 0x0f, 0x01, 0x0e}, //'y'
+	{0x00, 0x00, 0x1f, 0x02, 0x04, 0x08, 0x1f}, //'z'
+	{0x02, 0x04, 0x04, 0x08, 0x04, 0x04, 0x02}, //'{'
+	{0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, //'|'
+	{0x08, 0x04, 0x04, 0x02, 0x04, 0x04, 0x08}, //'}'
+	{0x00, 0x00, 0x08, 0x15, 0x02, 0x00, 0x00}, //'~'
+
+}
@@ -0,0 +1,97 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestRenderSubtitle(t *testing.T) {
+	vf, data := newTestVideoFrame(FourCCTypeBGRA, 200, 100, 4, func(x, y int) byte { return 100 })
+	pixel := func(x, y int) [4]byte {
+		var px [4]byte
+		copy(px[:], data[y*800+x*4:])
+		return px
+	}
+
+	if err := RenderSubtitle(vf, "HI", SubtitleOptions{Size: 8, Box: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	//One line of 8 pixels and the box padding above the bottom margin of 5 pixels, centered.
+	checks := []struct {
+		x, y int
+		want [4]byte
+	}{
+		{94, 85, [4]byte{255, 255, 255, 255}},
+		{95, 85, [4]byte{40, 40, 40, 193}},
+		{92, 83, [4]byte{40, 40, 40, 193}},
+		{91, 83, [4]byte{100, 100, 100, 100}},
+		{94, 96, [4]byte{100, 100, 100, 100}},
+	}
+	for _, c := range checks {
+		if px := pixel(c.x, c.y); px != c.want {
+			t.Errorf("Expected %v at %d,%d but got %v.", c.want, c.x, c.y, px)
+		}
+	}
+
+	//Twice the size at a custom position, partly outside of the frame.
+	red := color.NRGBA{255, 0, 0, 255}
+	if err := RenderSubtitle(vf, "|", SubtitleOptions{Size: 16, Position: SubtitleCustom, X: 190, Y: -2, TextColor: red}); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []int{194, 195} {
+		if px := pixel(x, 0); px != [4]byte{0, 0, 255, 255} {
+			t.Errorf("Expected red at %d,0 but got %v.", x, px)
+		}
+	}
+
+	//White on black UYVY.
+	uyvy, uyvyData := newTestVideoFrame(FourCCTypeUYVY, 200, 100, 2, func(x, y int) byte { return 16 })
+	for i := 0; i < len(uyvyData); i += 4 {
+		uyvyData[i], uyvyData[i+2] = 128, 128
+	}
+	if err := RenderSubtitle(uyvy, "HI", SubtitleOptions{Size: 8, Position: SubtitleTop}); err != nil {
+		t.Fatal(err)
+	}
+	//The top margin is 5 pixels, followed by the padding of 2.
+	if luma := uyvyData[7*400+94*2+1]; luma != 235 {
+		t.Errorf("Expected white luma at 94,7 but got %d.", luma)
+	}
+	if luma := uyvyData[7*400+95*2+1]; luma != 16 {
+		t.Errorf("Expected black luma at 95,7 but got %d.", luma)
+	}
+
+	tests := []struct {
+		name string
+		vf   *VideoFrameV2
+		opts SubtitleOptions
+		err  error
+	}{
+		{"nil", nil, SubtitleOptions{}, invalidVideoFrameErr},
+		{"FourCC", &VideoFrameV2{FourCC: FourCCTypeUYVA, Xres: 2, Yres: 2}, SubtitleOptions{}, unsupportedFourCCErr},
+		{"safe area", vf, SubtitleOptions{SafeArea: 0.5}, invalidSafeAreaErr},
+	}
+	for _, test := range tests {
+		if err := RenderSubtitle(test.vf, "x", test.opts); err != test.err {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.err, err)
+		}
+	}
+}
+
+func TestWrapSubtitle(t *testing.T) {
+	//Six pixels per character.
+	lines := wrapSubtitle("the quick brown fox\njumps", basicSubtitleFont{}, 1, 60)
+	if want := []string{"the quick", "brown fox", "jumps"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q but got %q.", want, lines)
+	}
+
+	lines = wrapSubtitle("extraordinarily long", basicSubtitleFont{}, 2, 60)
+	if want := []string{"extraordinarily", "long"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q but got %q.", want, lines)
+	}
+}
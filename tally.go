@@ -0,0 +1,137 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//TallyTarget is what a TallyAggregator reports tally through. *RecvInstance implements it.
+type TallyTarget interface {
+	SetTally(tally *Tally) bool
+}
+
+//TallyAggregator combines the tally wishes of several consumers watching the same source through different
+//receivers. Per source it sends the OR of all wishes through a single designated receiver, so the consumers
+//do not overwrite each other's tally.
+type TallyAggregator struct {
+	mu      sync.Mutex
+	sources map[string][]*TallyConsumer
+}
+
+//TallyConsumer is the handle of one consumer registered with a TallyAggregator.
+type TallyConsumer struct {
+	agg    *TallyAggregator
+	source string
+	target TallyTarget
+
+	//Guarded by agg.mu.
+	tally Tally
+}
+
+func NewTallyAggregator() *TallyAggregator {
+	return &TallyAggregator{sources: make(map[string][]*TallyConsumer)}
+}
+
+//Register adds a consumer that watches source through target. The consumer starts out wanting neither program
+//nor preview. Close must be called before target is destroyed.
+func (a *TallyAggregator) Register(source string, target TallyTarget) *TallyConsumer {
+	c := &TallyConsumer{agg: a, source: source, target: target}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sources[source] = append(a.sources[source], c)
+	a.update(source)
+	return c
+}
+
+//Refresh sends the combined tally of source again, for instance after its receiver reconnected.
+func (a *TallyAggregator) Refresh(source string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.update(source)
+}
+
+//Sends the combined tally of source through the designated receiver, which is the one of the oldest consumer.
+//Must be called with a.mu held.
+func (a *TallyAggregator) update(source string) {
+	consumers := a.sources[source]
+	if len(consumers) == 0 {
+		return
+	}
+
+	var tally Tally
+	for _, c := range consumers {
+		tally.OnProgram = tally.OnProgram || c.tally.OnProgram
+		tally.OnPreview = tally.OnPreview || c.tally.OnPreview
+	}
+	consumers[0].target.SetTally(&tally)
+}
+
+func (c *TallyConsumer) WantProgram(on bool) {
+	c.agg.mu.Lock()
+	defer c.agg.mu.Unlock()
+
+	c.tally.OnProgram = on
+	c.agg.update(c.source)
+}
+
+func (c *TallyConsumer) WantPreview(on bool) {
+	c.agg.mu.Lock()
+	defer c.agg.mu.Unlock()
+
+	c.tally.OnPreview = on
+	c.agg.update(c.source)
+}
+
+//Close unregisters the consumer. If its receiver was the designated one, the tally is cleared on it and the
+//combined tally is handed over to the receiver of the next consumer.
+func (c *TallyConsumer) Close() {
+	a := c.agg
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	consumers := a.sources[c.source]
+	for i, o := range consumers {
+		if o != c {
+			continue
+		}
+
+		if i == 0 {
+			c.target.SetTally(&Tally{})
+		}
+
+		consumers = append(consumers[:i:i], consumers[i+1:]...)
+		if len(consumers) == 0 {
+			delete(a.sources, c.source)
+		} else {
+			a.sources[c.source] = consumers
+		}
+		break
+	}
+	a.update(c.source)
+}
+
+//StartTallyPoller sends tally to the source right away and then again every interval until ctx is cancelled,
+//for sources that reset their tally when it is not refreshed.
+func (inst *RecvInstance) StartTallyPoller(ctx context.Context, tally Tally, interval time.Duration) {
+	go func() {
+		ticker := sysClock.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			inst.SetTally(&tally)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+}
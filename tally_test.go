@@ -0,0 +1,96 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"sync"
+	"testing"
+)
+
+type fakeTallyTarget struct {
+	mu    sync.Mutex
+	tally Tally
+	calls int
+}
+
+func (f *fakeTallyTarget) SetTally(tally *Tally) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tally = *tally
+	f.calls++
+	return true
+}
+
+func (f *fakeTallyTarget) get() (Tally, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tally, f.calls
+}
+
+func TestTallyAggregator(t *testing.T) {
+	agg := NewTallyAggregator()
+
+	var r1, r2, r3 fakeTallyTarget
+	c1 := agg.Register("CAM1", &r1)
+	c2 := agg.Register("CAM1", &r2)
+	c3 := agg.Register("CAM1", &r3)
+
+	c2.WantProgram(true)
+	c3.WantPreview(true)
+	if tally, _ := r1.get(); tally != (Tally{true, true}) {
+		t.Errorf("Expected program and preview on the designated receiver but got %+v.", tally)
+	}
+	if _, calls := r2.get(); calls != 0 {
+		t.Errorf("Only the designated receiver should report tally, but another one was called %d times.", calls)
+	}
+
+	c2.WantProgram(false)
+	if tally, _ := r1.get(); tally != (Tally{false, true}) {
+		t.Errorf("Expected only preview but got %+v.", tally)
+	}
+
+	//The designated consumer goes away, the next one takes over.
+	c3.WantProgram(true)
+	c1.Close()
+	if tally, _ := r1.get(); tally != (Tally{}) {
+		t.Errorf("Expected the tally to be cleared on the old receiver but got %+v.", tally)
+	}
+	if tally, _ := r2.get(); tally != (Tally{true, true}) {
+		t.Errorf("Expected the new designated receiver to take over but got %+v.", tally)
+	}
+
+	c2.Close()
+	c3.Close()
+	if tally, _ := r3.get(); tally != (Tally{}) {
+		t.Errorf("Expected no tally once every consumer is gone but got %+v.", tally)
+	}
+}
+
+func TestTallyAggregatorConcurrent(t *testing.T) {
+	agg := NewTallyAggregator()
+	var keep fakeTallyTarget
+	c := agg.Register("CAM1", &keep)
+	c.WantPreview(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var r fakeTallyTarget
+				c := agg.Register("CAM1", &r)
+				c.WantProgram(true)
+				agg.Refresh("CAM1")
+				c.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tally, _ := keep.get(); tally != (Tally{false, true}) {
+		t.Errorf("Expected only preview after the churn but got %+v.", tally)
+	}
+}
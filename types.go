@@ -5,10 +5,12 @@
 package ndi
 
 import (
-	"fmt"
+	"bytes"
+	"errors"
 	"math"
 	"reflect"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -22,12 +24,33 @@ func goStringFromConst(p uintptr) string {
 	return string(*(*[]byte)(unsafe.Pointer(h)))
 }
 
+//Returns a NULL terminated copy of s, or nil for an empty string.
+func cString(s string) *byte {
+	if s == "" {
+		return nil
+	}
+
+	b := make([]byte, len(s)+1)
+	copy(b, s)
+	return &b[0]
+}
+
 func goStringFromCString(p uintptr) string {
-	s := ""
-	for ; *(*byte)(unsafe.Pointer(p)) != 0; p++ {
-		s = fmt.Sprintf("%s%c", s, *(*byte)(unsafe.Pointer(p)))
+	return goString((*byte)(unsafe.Pointer(p)))
+}
+
+//Copies the NULL terminated UTF8 string at p, which may be nil. Walking the string from a pointer rather than a
+//uintptr keeps it within its allocation, which checkptr verifies for strings in Go memory.
+func goString(p *byte) string {
+	if p == nil {
+		return ""
 	}
-	return s
+
+	n := 0
+	for *(*byte)(unsafe.Add(unsafe.Pointer(p), n)) != 0 {
+		n++
+	}
+	return string(unsafe.Slice(p, n))
 }
 
 type Error struct {
@@ -38,6 +61,18 @@ func (e *Error) Timeout() bool {
 	return e.Errno.Timeout() || uintptr(e.Errno) == 1460
 }
 
+//SDKError carries the error text the NDI runtime reported for a failed operation.
+type SDKError struct {
+	Op, Message string
+}
+
+func (e *SDKError) Error() string {
+	if e.Message == "" {
+		return "ndi: " + e.Op + " failed"
+	}
+	return "ndi: " + e.Op + ": " + e.Message
+}
+
 type FrameFormat int32
 
 const (
@@ -64,12 +99,40 @@ const (
 )
 
 var (
+	//8-bit YCbCr 4:2:2 in U0, Y0, V0, Y1 byte order, 2 bytes per pixel.
 	FourCCTypeUYVY = [4]byte{'U', 'Y', 'V', 'Y'}
 
-	//BGRA
+	//10-bit YCbCr 4:2:2 packed into 32-bit little endian words, three components per word and six pixels in four
+	//words. Lines are padded to a multiple of 48 pixels, so the stride is (xres+47)/48*128 bytes.
+	FourCCTypeV210 = [4]byte{'V', '2', '1', '0'}
+
+	//16-bit YCbCr 4:2:2 in two planes. The Y plane of 16-bit little endian values is followed by a plane of
+	//interleaved Cb and Cr values with the same stride. The stride is at least xres*2 bytes.
+	FourCCTypeP216 = [4]byte{'P', '2', '1', '6'}
+
+	//P216 followed by a plane of 16-bit little endian alpha values, again with the same stride, so the data is
+	//LineStride*Yres*3 bytes.
+	FourCCTypePA16 = [4]byte{'P', 'A', '1', '6'}
+
+	//8-bit YCbCr 4:2:0 in three planes. The Y plane is followed by the Cr and then the Cb plane, which have half
+	//the stride and half the lines of the Y plane. I420 swaps the order of the Cb and Cr planes.
+	FourCCTypeYV12 = [4]byte{'Y', 'V', '1', '2'}
+	FourCCTypeI420 = [4]byte{'I', '4', '2', '0'}
+
+	//8-bit YCbCr 4:2:0 in two planes. The Y plane is followed by a plane of interleaved Cb and Cr values with the
+	//same stride and half the lines.
+	FourCCTypeNV12 = [4]byte{'N', 'V', '1', '2'}
+
+	//8 bits per component in B, G, R, A byte order, 4 bytes per pixel. BGRX has the same layout with the alpha
+	//byte ignored and expected to be 255.
 	FourCCTypeBGRA = [4]byte{'B', 'G', 'R', 'A'}
 	FourCCTypeBGRX = [4]byte{'B', 'G', 'R', 'X'}
 
+	//8 bits per component in R, G, B, A byte order, 4 bytes per pixel. RGBX has the same layout with the alpha
+	//byte ignored and expected to be 255.
+	FourCCTypeRGBA = [4]byte{'R', 'G', 'B', 'A'}
+	FourCCTypeRGBX = [4]byte{'R', 'G', 'B', 'X'}
+
 	//This is a UYVY buffer followed immediately by an alpha channel buffer.
 	//If the stride of the YCbCr component is "stride", then the alpha channel
 	//starts at image_ptr + yres*stride. The alpha channel stride is stride/2.
@@ -86,8 +149,24 @@ const (
 
 	//Read the SDK documentation to understand the pros and cons of this format.
 	RecvColorFormatFastest RecvColorFormat = 100
+
+	//The format closest to what the source sends, which may be 16-bit P216 or PA16 for high bit depth sources.
+	RecvColorFormatBest RecvColorFormat = 101
+
+	//Like RecvColorFormatBGRXBGRA, but vertically flipped for Windows DIBs. Only defined by the Windows SDK.
+	RecvColorFormatBGRXBGRAFlipped RecvColorFormat = 1000 + RecvColorFormatBGRXBGRA
 )
 
+//Reports whether f is one of the color formats the SDK defines.
+func IsValidRecvColorFormat(f RecvColorFormat) bool {
+	switch f {
+	case RecvColorFormatBGRXBGRA, RecvColorFormatUYVYBGRA, RecvColorFormatRGBXRGBA, RecvColorFormatUYVYRGBA,
+		RecvColorFormatFastest, RecvColorFormatBest, RecvColorFormatBGRXBGRAFlipped:
+		return true
+	}
+	return false
+}
+
 type FrameType int32
 
 //An enumeration to specify the type of a packet returned by the functions
@@ -162,10 +241,89 @@ func (vf *VideoFrameV2) SetDefault() {
 	vf.Timestamp = SendTimecodeEmpty
 }
 
+//Returns the LineStride*Yres bytes of video data, or nil if there is none. The slice aliases the frame data,
+//so it is only valid until the frame is freed.
 func (vf *VideoFrameV2) ReadData() []byte {
-	v := (*[1920 * 1080 * 4]byte)(unsafe.Pointer(vf.Data)) // Read
-	b := v[:vf.LineStride]
-	return b
+	n := int(vf.LineStride) * int(vf.Yres)
+	if vf.Data == nil || n <= 0 {
+		return nil
+	}
+	return unsafe.Slice(vf.Data, n)
+}
+
+//Returns the size of the video data, LineStride*Yres bytes plus the extra planes of UYVA, P216 and PA16.
+func (vf *VideoFrameV2) dataSize() int {
+	n := int(vf.LineStride) * int(vf.Yres)
+	switch vf.FourCC {
+	case FourCCTypeUYVA:
+		n += int(vf.LineStride/2) * int(vf.Yres)
+	case FourCCTypeP216:
+		n *= 2
+	case FourCCTypePA16:
+		n *= 3
+	case FourCCTypeYV12, FourCCTypeI420:
+		n += 2 * int(vf.LineStride/2) * int(vf.Yres/2)
+	case FourCCTypeNV12:
+		n += int(vf.LineStride) * int(vf.Yres/2)
+	}
+	return n
+}
+
+//Returns the dataSize bytes of video data. The slice aliases the frame data.
+func (vf *VideoFrameV2) data() []byte {
+	n := vf.dataSize()
+	if vf.Data == nil || n <= 0 {
+		return nil
+	}
+	return unsafe.Slice(vf.Data, n)
+}
+
+//Returns a copy of the frame whose data and metadata are owned by Go, so that it stays valid after the
+//original has been freed.
+func (vf *VideoFrameV2) clone() *VideoFrameV2 {
+	c := *vf
+	if d := vf.data(); d != nil {
+		c.Data = &append([]byte(nil), d...)[0]
+	}
+	if vf.Metadata != nil {
+		c.Metadata = cString(goString(vf.Metadata))
+	}
+	return &c
+}
+
+var externalDataTooSmallErr = errors.New("external buffer is smaller than the frame")
+
+//ExternalVideoFrame is a video frame whose data lives in memory that is not managed by Go, like a shared memory
+//mapping, so that it can be sent without copying. It keeps the size of that buffer next to the frame, which
+//SendVideoV2Checked and SendVideoAsyncV2Checked compare with LineStride and Yres before sending.
+type ExternalVideoFrame struct {
+	VideoFrameV2
+	size int
+}
+
+func NewExternalVideoFrame() *ExternalVideoFrame {
+	f := &ExternalVideoFrame{}
+	f.SetDefault()
+	return f
+}
+
+//Points the frame at ptr, a buffer of size bytes. The caller guarantees that the memory stays valid until the SDK
+//is done with it, which for SendVideoAsyncV2 is the next send. Passing nil detaches the buffer.
+func (f *ExternalVideoFrame) SetExternalData(ptr unsafe.Pointer, size int) {
+	f.Data = (*byte)(ptr)
+	f.size = size
+	if ptr == nil {
+		f.size = 0
+	}
+}
+
+//Returns an error if the frame does not fit into its buffer. SendVideoV2Checked and SendVideoAsyncV2Checked call
+//it before sending.
+func (f *ExternalVideoFrame) CheckSize() error {
+	if f.Data != nil && f.dataSize() > f.size {
+		return externalDataTooSmallErr
+	}
+	return nil
 }
 
 func NewAudioFrameV2() *AudioFrameV2 {
@@ -203,6 +361,166 @@ func (af *AudioFrameV2) SetDefault() {
 	af.Timestamp = SendTimecodeEmpty
 }
 
+type FourCCAudioType uint32
+
+const (
+	//Planar 32-bit floating point. Be sure to specify the channel stride.
+	FourCCAudioTypeFLTP FourCCAudioType = 'F' | 'L'<<8 | 'T'<<16 | 'p'<<24
+)
+
+func NewAudioFrameV3() *AudioFrameV3 {
+	af := &AudioFrameV3{}
+	af.SetDefault()
+	return af
+}
+
+//This describes an audio frame of the NDI 4 API, which also allows compressed audio.
+type AudioFrameV3 struct {
+	SampleRate, //The sample-rate of this buffer.
+	NumChannels, //The number of audio channels.
+	NumSamples int32 //The number of audio samples per channel.
+	Timecode int64           //The timecode of this frame in 100ns intervals.
+	FourCC   FourCCAudioType //What FourCC describing the type of data for this frame.
+	Data     *byte           //The audio data.
+
+	//The inter channel stride of the audio channels in bytes for uncompressed FourCCs, the size of the data in
+	//bytes for compressed ones.
+	ChannelStride int32
+
+	//Per frame metadata for this frame. This is a NULL terminated UTF8 string that should be
+	//in XML format. If you do not want any metadata then you may specify NULL here.
+	Metadata *byte
+
+	//This is only valid when receiving a frame and is specified as a 100ns time that was the exact
+	//moment that the frame was submitted by the sending side and is generated by the SDK.
+	Timestamp int64
+}
+
+func (af *AudioFrameV3) SetDefault() {
+	af.SampleRate = 48000
+	af.NumChannels = 2
+	af.NumSamples = 0
+	af.Timecode = SendTimecodeSynthesize
+	af.FourCC = FourCCAudioTypeFLTP
+	af.Data = nil
+	af.ChannelStride = 0
+	af.Metadata = nil
+	af.Timestamp = SendTimecodeEmpty
+}
+
+//Returns an AudioFrameV3 describing the same audio. The data is not copied, so the result is only valid as long as af.
+func (af *AudioFrameV2) ToV3() *AudioFrameV3 {
+	return &AudioFrameV3{
+		SampleRate:    af.SampleRate,
+		NumChannels:   af.NumChannels,
+		NumSamples:    af.NumSamples,
+		Timecode:      af.Timecode,
+		FourCC:        FourCCAudioTypeFLTP,
+		Data:          (*byte)(unsafe.Pointer(af.Data)),
+		ChannelStride: af.ChannelStride,
+		Metadata:      af.Metadata,
+		Timestamp:     af.Timestamp,
+	}
+}
+
+//Returns an AudioFrameV2 describing the same audio, or nil if the audio is not FLTP, the only format AudioFrameV2
+//can describe. The data is not copied, so the result is only valid as long as af.
+func (af *AudioFrameV3) ToV2() *AudioFrameV2 {
+	if af.FourCC != FourCCAudioTypeFLTP {
+		return nil
+	}
+	return &AudioFrameV2{
+		SampleRate:    af.SampleRate,
+		NumChannels:   af.NumChannels,
+		NumSamples:    af.NumSamples,
+		Timecode:      af.Timecode,
+		Data:          (*float32)(unsafe.Pointer(af.Data)),
+		ChannelStride: af.ChannelStride,
+		Metadata:      af.Metadata,
+		Timestamp:     af.Timestamp,
+	}
+}
+
+//Returns all NumChannels*NumSamples samples, or nil if there is no data. Only meaningful when the channels are
+//packed back to back, that is ChannelStride is NumSamples*4, otherwise use ReadChannel. The slice aliases the
+//frame data, so it is only valid until the frame is freed.
+func (af *AudioFrameV2) ReadSamples() []float32 {
+	n := int(af.NumChannels) * int(af.NumSamples)
+	if af.Data == nil || n <= 0 {
+		return nil
+	}
+	return unsafe.Slice(af.Data, n)
+}
+
+//Returns the NumSamples samples of channel ch, or nil if there is no data or no such channel. The slice aliases
+//the frame data, so it is only valid until the frame is freed.
+func (af *AudioFrameV2) ReadChannel(ch int) []float32 {
+	n := int(af.NumSamples)
+	if af.Data == nil || n <= 0 || ch < 0 || ch >= int(af.NumChannels) {
+		return nil
+	}
+
+	p := (*float32)(unsafe.Add(unsafe.Pointer(af.Data), ch*int(af.ChannelStride)))
+	return unsafe.Slice(p, n)
+}
+
+//Interleaved 16-bit signed audio, as taken by SendInstance.SendAudioInterleaved16s.
+type AudioFrameInterleaved16s struct {
+	SampleRate, //The sample-rate of this buffer.
+	NumChannels, //The number of audio channels.
+	NumSamples int32 //The number of audio samples per channel.
+	Timecode int64 //The timecode of this frame in 100ns intervals.
+
+	//The audio reference level in dB. This specifies how many dB above the reference level (+4dBU) is the full range
+	//of 16-bit audio. 0 maps full range to +4dBU, 20 to +24dBU.
+	ReferenceLevel int32
+
+	Data *int16 //The NumChannels*NumSamples samples, the channels of each sample side by side.
+}
+
+func NewAudioFrameInterleaved16s() *AudioFrameInterleaved16s {
+	return &AudioFrameInterleaved16s{SampleRate: 48000, NumChannels: 2, Timecode: SendTimecodeSynthesize}
+}
+
+//Interleaved 32-bit signed audio, as taken by SendInstance.SendAudioInterleaved32s.
+type AudioFrameInterleaved32s struct {
+	SampleRate, //The sample-rate of this buffer.
+	NumChannels, //The number of audio channels.
+	NumSamples int32 //The number of audio samples per channel.
+	Timecode int64 //The timecode of this frame in 100ns intervals.
+
+	//The audio reference level in dB, like in AudioFrameInterleaved16s but for the full range of 32-bit audio.
+	ReferenceLevel int32
+
+	Data *int32 //The NumChannels*NumSamples samples, the channels of each sample side by side.
+}
+
+func NewAudioFrameInterleaved32s() *AudioFrameInterleaved32s {
+	return &AudioFrameInterleaved32s{SampleRate: 48000, NumChannels: 2, Timecode: SendTimecodeSynthesize}
+}
+
+//Interleaved 32-bit floating point audio, as taken by SendInstance.SendAudioInterleaved32f. There is no reference
+//level, 1.0 is +4dBU like in the planar frames.
+type AudioFrameInterleaved32f struct {
+	SampleRate, //The sample-rate of this buffer.
+	NumChannels, //The number of audio channels.
+	NumSamples int32 //The number of audio samples per channel.
+	Timecode int64    //The timecode of this frame in 100ns intervals.
+	Data     *float32 //The NumChannels*NumSamples samples, the channels of each sample side by side.
+}
+
+func NewAudioFrameInterleaved32f() *AudioFrameInterleaved32f {
+	return &AudioFrameInterleaved32f{SampleRate: 48000, NumChannels: 2, Timecode: SendTimecodeSynthesize}
+}
+
+//Checks the sizes of an interleaved frame, which needs data as soon as it has samples.
+func checkInterleavedAudio(numChannels, numSamples int32, hasData bool) error {
+	if numChannels < 0 || numSamples < 0 || (numChannels > 0 && numSamples > 0 && !hasData) {
+		return invalidAudioFrameErr
+	}
+	return nil
+}
+
 func NewRecvCreateSettings() *RecvCreateSettings {
 	s := &RecvCreateSettings{}
 	s.SetDefault()
@@ -257,6 +575,60 @@ func (mf *MetadataFrame) SetDefault() {
 	mf.Data = nil
 }
 
+//Returns the metadata as a Go string, or an empty string if there is none. When Length is set no more than
+//Length bytes are read, otherwise the data is read up to its NULL terminator.
+func (mf *MetadataFrame) ReadString() string {
+	if mf.Data == nil {
+		return ""
+	}
+	if mf.Length <= 0 {
+		return goString(mf.Data)
+	}
+
+	b := unsafe.Slice(mf.Data, mf.Length)
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+//Frame counters of a receiver, see RecvInstance.GetPerformance.
+type RecvPerformance struct {
+	VideoFrames, AudioFrames, MetadataFrames int64
+}
+
+//The number of frames waiting in the queues of a receiver, see RecvInstance.GetQueue.
+type RecvQueue struct {
+	VideoFrames, AudioFrames, MetadataFrames int32
+}
+
+//Matches NDIlib_recv_recording_time_t, the times are in 100ns units.
+type recordingTime struct {
+	noFrames, startTime, lastTime int64
+}
+
+//Progress of a recording, see RecvInstance.RecordingGetTimes.
+type RecordingTimes struct {
+	//The number of frames recorded so far.
+	Frames int64
+
+	//The time of the first and the latest frame that was recorded, in UTC.
+	Start, Last time.Time
+}
+
+//The length of the recording so far.
+func (t RecordingTimes) Length() time.Duration {
+	return t.Last.Sub(t.Start)
+}
+
+func (t recordingTime) toGo() RecordingTimes {
+	return RecordingTimes{
+		Frames: t.noFrames,
+		Start:  time.Unix(0, t.startTime*100).UTC(),
+		Last:   time.Unix(0, t.lastTime*100).UTC(),
+	}
+}
+
 //This is a private struct!
 type ndiLIBv5 struct {
 	// V1.5
@@ -386,5 +758,5 @@ type ndiLIBv5 struct {
 	NDIlibFramesyncAudioQueueDepth, // int(*framesync_audio_queue_depth)(NDIlib_framesync_instance_t p_instance)
 
 	// v4.5
-	NDIlibRecvPtzExposureManualV2 uintptr //bool(*recv_ptz_exposure_manual_v2)(NDIlib_framesync_instance_t p_instance, const float iris, const float gain, const float shutter_speed)
+	NDIlibRecvPtzExposureManualV2 uintptr //bool(*recv_ptz_exposure_manual_v2)(NDIlib_recv_instance_t p_instance, const float iris, const float gain, const float shutter_speed)
 }
@@ -7,16 +7,19 @@ package ndi
 import (
 	"reflect"
 	"testing"
+	"time"
+	"unsafe"
 )
 
 var fieldAlignments = map[string]int{
-	"bool":        1,
-	"int":         4,
-	"int32":       4,
-	"int64":       8,
-	"uint32":      4,
-	"float32":     4,
-	"FrameFormat": 4,
+	"bool":            1,
+	"int":             4,
+	"int32":           4,
+	"int64":           8,
+	"uint32":          4,
+	"float32":         4,
+	"FrameFormat":     4,
+	"FourCCAudioType": 4,
 }
 
 func fieldAlignmentTest(t *testing.T, v interface{}) {
@@ -45,6 +48,15 @@ func TestFieldAlignment(t *testing.T) {
 	var vf VideoFrameV2
 	fieldAlignmentTest(t, vf)
 
+	var af3 AudioFrameV3
+	fieldAlignmentTest(t, af3)
+
+	fieldAlignmentTest(t, AudioFrameInterleaved16s{})
+	fieldAlignmentTest(t, AudioFrameInterleaved32s{})
+	fieldAlignmentTest(t, AudioFrameInterleaved32f{})
+	fieldAlignmentTest(t, RecvPerformance{})
+	fieldAlignmentTest(t, RecvQueue{})
+
 	var scs SendCreateSettings
 	fieldAlignmentTest(t, scs)
 
@@ -65,9 +77,253 @@ func TestStructSizes(t *testing.T) {
 	var af AudioFrameV2
 	checkTypeSize(t, af, 56)
 
+	var af3 AudioFrameV3
+	checkTypeSize(t, af3, 64)
+
+	checkTypeSize(t, AudioFrameInterleaved16s{}, 40)
+	checkTypeSize(t, AudioFrameInterleaved32s{}, 40)
+	checkTypeSize(t, AudioFrameInterleaved32f{}, 32)
+	checkTypeSize(t, recordingTime{}, 24)
+	checkTypeSize(t, RecvPerformance{}, 24)
+	checkTypeSize(t, RecvQueue{}, 12)
+
 	var scs SendCreateSettings
 	checkTypeSize(t, scs, 24)
 
 	var fcs FindCreateSettings
 	checkTypeSize(t, fcs, 24)
 }
+
+//The function table is filled from the struct the runtime returns, so each entry must sit at the index of its
+//export in NDIlib_v5. These are the first entries of every SDK version and the last entry.
+func TestFunctionTableOffsets(t *testing.T) {
+	var table ndiLIBv5
+	ptr := unsafe.Sizeof(uintptr(0))
+	offsets := []struct {
+		name   string
+		offset uintptr
+		index  uintptr
+	}{
+		{"find_wait_for_sources", unsafe.Offsetof(table.NDIlibFindWaitForSources), 42},
+		{"recv_free_video_v2", unsafe.Offsetof(table.NDIlibRecvFreeVideoV2), 47},
+		{"recv_ptz_exposure_manual", unsafe.Offsetof(table.NDIlibRecvPtzExposureManual), 76},
+		{"recv_create_v3", unsafe.Offsetof(table.NDIlibRecvInstanceT), 84},
+		{"framesync_create", unsafe.Offsetof(table.NDIlibFramesyncInstanceT), 86},
+		{"send_get_source_name", unsafe.Offsetof(table.NDIlibSourceTv38), 95},
+		{"send_send_audio_v3", unsafe.Offsetof(table.NDIlibSendSendAudioV3), 96},
+		{"routing_get_no_connections", unsafe.Offsetof(table.NDIlibRoutingGetNoConnections), 99},
+		{"recv_ptz_exposure_manual_v2", unsafe.Offsetof(table.NDIlibRecvPtzExposureManualV2), 106},
+	}
+	for _, o := range offsets {
+		if o.offset != o.index*ptr {
+			t.Errorf("Expected %s at index %d but it is at %d.", o.name, o.index, o.offset/ptr)
+		}
+	}
+	if size := unsafe.Sizeof(table); size != 107*ptr {
+		t.Errorf("Expected 107 entries but got %d.", size/ptr)
+	}
+}
+
+func TestRecordingTimes(t *testing.T) {
+	times := recordingTime{noFrames: 300, startTime: 16000000000000000, lastTime: 16000000100000000}.toGo()
+	if times.Frames != 300 {
+		t.Errorf("Expected 300 frames but got %d.", times.Frames)
+	}
+	if want := time.Unix(1600000000, 0).UTC(); times.Start != want {
+		t.Errorf("Expected the recording to start at %v but got %v.", want, times.Start)
+	}
+	if want := time.Unix(1600000010, 0).UTC(); times.Last != want {
+		t.Errorf("Expected the last frame at %v but got %v.", want, times.Last)
+	}
+	if length := times.Length(); length != 10*time.Second {
+		t.Errorf("Expected a length of 10s but got %v.", length)
+	}
+}
+
+//GetPerformance fills NDIlib_recv_performance_t in place, the counters must follow each other as in the C struct.
+func TestRecvPerformanceLayout(t *testing.T) {
+	var perf RecvPerformance
+	offsets := []uintptr{unsafe.Offsetof(perf.VideoFrames), unsafe.Offsetof(perf.AudioFrames), unsafe.Offsetof(perf.MetadataFrames)}
+	for i, offset := range offsets {
+		if offset != uintptr(i)*8 {
+			t.Errorf("Expected counter %d at offset %d but got %d.", i, i*8, offset)
+		}
+	}
+}
+
+func TestReadData(t *testing.T) {
+	resolutions := []struct{ xres, yres int32 }{
+		{720, 480},
+		{1280, 720},
+		{1920, 1080},
+		{3840, 2160},
+	}
+	formats := []struct {
+		fourCC        [4]byte
+		bytesPerPixel int32
+	}{
+		{FourCCTypeBGRX, 4},
+		{FourCCTypeUYVY, 2},
+	}
+
+	for _, res := range resolutions {
+		for _, format := range formats {
+			stride := res.xres * format.bytesPerPixel
+			data := make([]byte, stride*res.yres)
+			data[len(data)-1] = 0xff
+
+			vf := NewVideoFrameV2()
+			vf.FourCC = format.fourCC
+			vf.Xres = res.xres
+			vf.Yres = res.yres
+			vf.LineStride = stride
+			vf.Data = &data[0]
+
+			b := vf.ReadData()
+			if len(b) != len(data) {
+				t.Errorf("%s %dx%d: expected %d bytes but got %d.", format.fourCC, res.xres, res.yres, len(data), len(b))
+			} else if b[len(b)-1] != 0xff {
+				t.Errorf("%s %dx%d: the last byte does not alias the frame data.", format.fourCC, res.xres, res.yres)
+			}
+		}
+	}
+
+	if b := NewVideoFrameV2().ReadData(); b != nil {
+		t.Errorf("Expected nil for a frame without data but got %d bytes.", len(b))
+	}
+}
+
+func TestReadSamples(t *testing.T) {
+	const (
+		numChannels = 3
+		numSamples  = 480
+	)
+
+	data := make([]float32, numChannels*numSamples)
+	for i := range data {
+		data[i] = float32(i)
+	}
+
+	af := NewAudioFrameV2()
+	af.NumChannels = numChannels
+	af.NumSamples = numSamples
+	af.ChannelStride = numSamples * 4
+	af.Data = &data[0]
+
+	samples := af.ReadSamples()
+	if len(samples) != len(data) {
+		t.Fatalf("Expected %d samples but got %d.", len(data), len(samples))
+	}
+	samples[0] = -1
+	if data[0] != -1 {
+		t.Error("ReadSamples does not alias the frame data.")
+	}
+
+	for ch := 0; ch < numChannels; ch++ {
+		channel := af.ReadChannel(ch)
+		if len(channel) != numSamples {
+			t.Fatalf("Expected %d samples in channel %d but got %d.", numSamples, ch, len(channel))
+		}
+		if &channel[0] != &data[ch*numSamples] {
+			t.Errorf("Channel %d does not start at the right offset.", ch)
+		}
+	}
+
+	if af.ReadChannel(numChannels) != nil || af.ReadChannel(-1) != nil {
+		t.Error("Expected nil for a channel out of range.")
+	}
+	if NewAudioFrameV2().ReadSamples() != nil {
+		t.Error("Expected nil for a frame without data.")
+	}
+}
+
+func TestMetadataReadString(t *testing.T) {
+	data := []byte("<ndi_tally on_program=\"true\"/>\x00garbage")
+
+	mf := NewMetadataFrame()
+	if s := mf.ReadString(); s != "" {
+		t.Errorf("Expected an empty string for a frame without data but got %q.", s)
+	}
+
+	mf.Data = &data[0]
+	if s := mf.ReadString(); s != `<ndi_tally on_program="true"/>` {
+		t.Errorf("Unexpected string %q when reading up to the terminator.", s)
+	}
+
+	mf.Length = 11
+	if s := mf.ReadString(); s != "<ndi_tally " {
+		t.Errorf("Unexpected string %q when reading Length bytes.", s)
+	}
+}
+
+func TestExternalVideoFrame(t *testing.T) {
+	buf := make([]byte, 1920*1080*2)
+
+	vf := NewExternalVideoFrame()
+	vf.FourCC = FourCCTypeUYVY
+	vf.Xres, vf.Yres = 1920, 1080
+	vf.LineStride = 1920 * 2
+	vf.SetExternalData(unsafe.Pointer(&buf[0]), len(buf))
+
+	if vf.Data != &buf[0] {
+		t.Error("Expected the frame to point at the external buffer.")
+	}
+	if err := vf.CheckSize(); err != nil {
+		t.Errorf("Expected the frame to fit but got %v.", err)
+	}
+
+	//The alpha plane of UYVA does not fit.
+	vf.FourCC = FourCCTypeUYVA
+	if err := vf.CheckSize(); err != externalDataTooSmallErr {
+		t.Errorf("Expected %v but got %v.", externalDataTooSmallErr, err)
+	}
+
+	vf.SetExternalData(nil, 0)
+	if err := vf.CheckSize(); err != nil {
+		t.Errorf("Expected a detached frame to pass but got %v.", err)
+	}
+}
+
+func TestVideoDataSize(t *testing.T) {
+	tests := []struct {
+		fourCC [4]byte
+		stride int32
+		size   int
+	}{
+		{FourCCTypeUYVY, 3840, 3840 * 1080},
+		{FourCCTypeRGBA, 7680, 7680 * 1080},
+		{FourCCTypeUYVA, 3840, 3840*1080 + 1920*1080},
+		{FourCCTypeV210, 5120, 5120 * 1080},
+		{FourCCTypeP216, 3840, 3840 * 1080 * 2},
+		{FourCCTypePA16, 3840, 3840 * 1080 * 3},
+		{FourCCTypeYV12, 1920, 1920*1080 + 2*960*540},
+		{FourCCTypeI420, 1920, 1920*1080 + 2*960*540},
+		{FourCCTypeNV12, 1920, 1920*1080 + 1920*540},
+	}
+	for _, test := range tests {
+		vf := &VideoFrameV2{FourCC: test.fourCC, Xres: 1920, Yres: 1080, LineStride: test.stride}
+		if size := vf.dataSize(); size != test.size {
+			t.Errorf("Expected %s to have %d bytes but got %d.", test.fourCC[:], test.size, size)
+		}
+	}
+}
+
+func TestAudioFrameConversion(t *testing.T) {
+	af := newPlanarAudioFrame([][]float32{{1, 2, 3}, {4, 5, 6}}, 44100)
+	af.Timecode = 1234
+
+	v3 := af.ToV3()
+	if v3.FourCC != FourCCAudioTypeFLTP || v3.SampleRate != 44100 || v3.NumChannels != 2 || v3.NumSamples != 3 || v3.ChannelStride != 12 || v3.Timecode != 1234 {
+		t.Errorf("Unexpected V3 frame %+v.", v3)
+	}
+
+	v2 := v3.ToV2()
+	if v2 == nil || !reflect.DeepEqual(v2.ReadChannel(1), []float32{4, 5, 6}) || v2.Timecode != 1234 {
+		t.Errorf("Expected the round trip to keep the audio but got %+v.", v2)
+	}
+
+	v3.FourCC = 0
+	if v3.ToV2() != nil {
+		t.Error("Expected no V2 frame for audio that is not FLTP.")
+	}
+}
@@ -0,0 +1,70 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+var videoConversionUnsupportedErr = errors.New("the loaded NDI runtime has no V210 and P216 conversions, it needs version 4.0 or later")
+
+//The minimum line stride of a frame of the given width in the formats the conversions take.
+func minLineStride(fourCC [4]byte, xres int32) int32 {
+	switch fourCC {
+	case FourCCTypeV210:
+		return (xres + 47) / 48 * 128
+	case FourCCTypeP216:
+		return xres * 2
+	}
+	return 0
+}
+
+//Checks the frames of a conversion, which must have the same resolution, the expected FourCCs and data.
+func checkVideoConversion(src, dst *VideoFrameV2, srcFourCC, dstFourCC [4]byte) error {
+	if src == nil || dst == nil || src.Data == nil || dst.Data == nil {
+		return invalidVideoFrameErr
+	}
+	if src.FourCC != srcFourCC || dst.FourCC != dstFourCC {
+		return unsupportedFourCCErr
+	}
+	if src.Xres != dst.Xres || src.Yres != dst.Yres {
+		return frameMismatchErr
+	}
+	if src.Xres <= 0 || src.Yres <= 0 || src.LineStride < minLineStride(srcFourCC, src.Xres) || dst.LineStride < minLineStride(dstFourCC, dst.Xres) {
+		return invalidVideoFrameErr
+	}
+	return nil
+}
+
+func convertVideo(fn uintptr, src, dst *VideoFrameV2) error {
+	if fn == 0 {
+		return videoConversionUnsupportedErr
+	}
+	if _, _, eno := syscall.Syscall(fn, 2, uintptr(unsafe.Pointer(src)), uintptr(unsafe.Pointer(dst)), 0); eno != 0 {
+		panic(eno)
+	}
+	return nil
+}
+
+//V210ToP216 converts a V210 frame to P216. The caller allocates dst with the resolution of src, the FourCC
+//FourCCTypeP216, a LineStride of at least Xres*2 and LineStride*Yres*2 bytes of data. Returns an error if the
+//runtime is older than 4.0, which lacks the conversions.
+func V210ToP216(src, dst *VideoFrameV2) error {
+	if err := checkVideoConversion(src, dst, FourCCTypeV210, FourCCTypeP216); err != nil {
+		return err
+	}
+	return convertVideo(funcPtrs.NDIlibUtilV210ToP216, src, dst)
+}
+
+//P216ToV210 converts a P216 frame to V210. The caller allocates dst with the resolution of src, the FourCC
+//FourCCTypeV210, a LineStride of at least (Xres+47)/48*128 and LineStride*Yres bytes of data. See V210ToP216.
+func P216ToV210(src, dst *VideoFrameV2) error {
+	if err := checkVideoConversion(src, dst, FourCCTypeP216, FourCCTypeV210); err != nil {
+		return err
+	}
+	return convertVideo(funcPtrs.NDIlibUtilP216ToV210, src, dst)
+}
@@ -0,0 +1,151 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+//SourceEvent lists the sources that appeared or disappeared since the previous event, sorted by name.
+type SourceEvent struct {
+	Added, Removed []Source
+}
+
+//SourceWatcher reports changes to the sources a finder sees as events on a channel.
+type SourceWatcher struct {
+	finder *FindInstance
+	events chan SourceEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+//NewSourceWatcher starts watching the sources of finder and takes ownership of it. The sources are checked
+//whenever the finder reports a change, at least every interval. The first event holds the sources that are
+//already known. Sources are identified by name, so a source that changes its address is not reported. Events
+//stop and the channel is closed once ctx is done or the watcher is closed, which can take up to interval.
+func NewSourceWatcher(ctx context.Context, finder *FindInstance, interval time.Duration) *SourceWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &SourceWatcher{
+		finder: finder,
+		events: make(chan SourceEvent),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go w.run(ctx, interval)
+	return w
+}
+
+func (w *SourceWatcher) Events() <-chan SourceEvent {
+	return w.events
+}
+
+//Close stops the watcher and destroys its finder once it has stopped.
+func (w *SourceWatcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+func (w *SourceWatcher) run(ctx context.Context, interval time.Duration) {
+	defer close(w.done)
+	defer w.finder.Destroy()
+	defer close(w.events)
+
+	known := make(map[string]Source)
+	for ctx.Err() == nil {
+		if ev := diffSources(known, w.finder.GetCurrentSources()); len(ev.Added) > 0 || len(ev.Removed) > 0 {
+			select {
+			case w.events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		w.finder.WaitForSourcesChanged(interval)
+	}
+}
+
+//Compares current against the sources known so far by name and updates known to match current.
+func diffSources(known map[string]Source, current []*Source) SourceEvent {
+	seen := make(map[string]struct{}, len(current))
+	var ev SourceEvent
+	for _, s := range current {
+		name := s.Name()
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		if _, ok := known[name]; !ok {
+			known[name] = *s
+			ev.Added = append(ev.Added, *s)
+		}
+	}
+	for name, s := range known {
+		if _, ok := seen[name]; !ok {
+			delete(known, name)
+			ev.Removed = append(ev.Removed, s)
+		}
+	}
+
+	sortSources(ev.Added)
+	sortSources(ev.Removed)
+	return ev
+}
+
+func sortSources(sources []Source) {
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Name() < sources[j].Name()
+	})
+}
+
+//How long SourcesChan waits for a change before checking whether it was cancelled.
+const sourcesChanPollInterval = 500 * time.Millisecond
+
+//SourcesChan sends the current sources of the finder every time they change, starting with the sources that are
+//already known. The channel is closed once ctx is done, which is noticed within half a second. The finder must
+//not be destroyed before that.
+func (inst *FindInstance) SourcesChan(ctx context.Context) <-chan []Source {
+	return watchSources(ctx, func() { inst.WaitForSourcesChanged(sourcesChanPollInterval) }, inst.GetCurrentSources)
+}
+
+func watchSources(ctx context.Context, wait func(), current func() []*Source) <-chan []Source {
+	ch := make(chan []Source)
+	go func() {
+		defer close(ch)
+
+		var last []Source
+		for first := true; ctx.Err() == nil; first = false {
+			ptrs := current()
+			sources := make([]Source, len(ptrs))
+			for i, s := range ptrs {
+				sources[i] = *s
+			}
+
+			if first || !sourcesEqual(sources, last) {
+				select {
+				case ch <- sources:
+					last = sources
+				case <-ctx.Done():
+					return
+				}
+			}
+			wait()
+		}
+	}()
+	return ch
+}
+
+func sourcesEqual(a, b []Source) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name() != b[i].Name() || a[i].Address() != b[i].Address() {
+			return false
+		}
+	}
+	return true
+}
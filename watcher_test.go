@@ -0,0 +1,86 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffSources(t *testing.T) {
+	sources := func(names ...string) []*Source {
+		ret := make([]*Source, len(names))
+		for i, name := range names {
+			s := NewSource(name, "")
+			ret[i] = &s
+		}
+		return ret
+	}
+	names := func(sources []Source) []string {
+		var ret []string
+		for _, s := range sources {
+			ret = append(ret, s.Name())
+		}
+		return ret
+	}
+
+	tests := []struct {
+		current        []*Source
+		added, removed []string
+	}{
+		{sources("B", "A"), []string{"A", "B"}, nil},
+		{sources("A", "B"), nil, nil},
+		{sources("B", "C", "C"), []string{"C"}, []string{"A"}},
+		{sources(), nil, []string{"B", "C"}},
+	}
+
+	known := make(map[string]Source)
+	for i, test := range tests {
+		ev := diffSources(known, test.current)
+		if added := names(ev.Added); !reflect.DeepEqual(added, test.added) {
+			t.Errorf("Poll %d: Expected %v to be added but got %v.", i, test.added, added)
+		}
+		if removed := names(ev.Removed); !reflect.DeepEqual(removed, test.removed) {
+			t.Errorf("Poll %d: Expected %v to be removed but got %v.", i, test.removed, removed)
+		}
+	}
+}
+
+func TestWatchSources(t *testing.T) {
+	polls := [][]string{{"A"}, {"A"}, {"A", "B"}, {"A", "B"}, {"B"}}
+	current := func() []*Source {
+		names := polls[0]
+		if len(polls) > 1 {
+			polls = polls[1:]
+		}
+		ret := make([]*Source, len(names))
+		for i, name := range names {
+			s := NewSource(name, "")
+			ret[i] = &s
+		}
+		return ret
+	}
+
+	useFakeClock(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := watchSources(ctx, func() { sysClock.Sleep(time.Millisecond) }, current)
+
+	for _, expected := range [][]string{{"A"}, {"A", "B"}, {"B"}} {
+		var names []string
+		for _, s := range <-ch {
+			names = append(names, s.Name())
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected %v but got %v.", expected, names)
+		}
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("Expected no update after the list stopped changing.")
+	}
+}
@@ -0,0 +1,133 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+type WaveformMode int
+
+const (
+	//One trace of the BT.709 luma of every column.
+	WaveformLuma WaveformMode = iota
+
+	//The red, green and blue values side by side, each in a third of the output.
+	WaveformRGBParade
+)
+
+type WaveformOptions struct {
+	Mode WaveformMode
+
+	//Brightness of the traces. At 1 a level is drawn at full brightness once it is hit as often as if the column was
+	//spread evenly over all levels. Zero means 1.
+	Intensity float32
+}
+
+//DrawWaveform renders a waveform monitor of vf into output, overwriting it. Every column of output shows the levels
+//of the corresponding columns of vf, from 0 at the bottom to 255 at the top. vf may be BGRA, BGRX, UYVY or UYVA,
+//which is converted with the BT.709 matrix. output must be BGRA or BGRX with its data allocated at the display size.
+func DrawWaveform(vf *VideoFrameV2, output *VideoFrameV2, opts WaveformOptions) error {
+	if vf == nil || output == nil || output.Xres <= 0 || output.Yres <= 0 {
+		return invalidVideoFrameErr
+	}
+	if output.FourCC != FourCCTypeBGRA && output.FourCC != FourCCTypeBGRX {
+		return unsupportedFourCCErr
+	}
+	outW, outH := int(output.Xres), int(output.Yres)
+	outData := output.data()
+	if outData == nil || int(output.LineStride) < outW*4 {
+		return invalidVideoFrameErr
+	}
+
+	src := vf
+	switch vf.FourCC {
+	case FourCCTypeUYVY, FourCCTypeUYVA:
+		var err error
+		if src, err = uyvyToBGRA(vf, bt709); err != nil {
+			return err
+		}
+	case FourCCTypeBGRA, FourCCTypeBGRX:
+	default:
+		return unsupportedFourCCErr
+	}
+	width, height := int(src.Xres), int(src.Yres)
+	srcData := src.data()
+	if width <= 0 || height <= 0 || srcData == nil || int(src.LineStride) < width*4 {
+		return invalidVideoFrameErr
+	}
+
+	//The traces, in BGR order, and the columns of the output each one is drawn to.
+	type trace struct {
+		color  [3]byte
+		x0, w  int
+		sample func(px []byte) byte
+	}
+	var traces []trace
+	if opts.Mode == WaveformRGBParade {
+		third := outW / 3
+		if third == 0 {
+			return invalidResolutionErr
+		}
+		traces = []trace{
+			{[3]byte{0, 0, 255}, 0, third, func(px []byte) byte { return px[2] }},
+			{[3]byte{0, 255, 0}, third, third, func(px []byte) byte { return px[1] }},
+			{[3]byte{255, 0, 0}, 2 * third, third, func(px []byte) byte { return px[0] }},
+		}
+	} else {
+		traces = []trace{{[3]byte{255, 255, 255}, 0, outW, func(px []byte) byte {
+			return clampByte(0.2126*float32(px[2]) + 0.7152*float32(px[1]) + 0.0722*float32(px[0]))
+		}}}
+	}
+
+	intensity := opts.Intensity
+	if intensity <= 0 {
+		intensity = 1
+	}
+
+	for y := 0; y < outH; y++ {
+		row := outData[y*int(output.LineStride) : y*int(output.LineStride)+outW*4]
+		for x := 0; x < len(row); x += 4 {
+			row[x], row[x+1], row[x+2], row[x+3] = 0, 0, 0, 255
+		}
+	}
+
+	hits := make([]int, outW*outH)
+	for _, tr := range traces {
+		for i := range hits[:tr.w*outH] {
+			hits[i] = 0
+		}
+		for y := 0; y < height; y++ {
+			row := srcData[y*int(src.LineStride):]
+			for x := 0; x < width; x++ {
+				level := int(tr.sample(row[x*4:]))
+				out := hits[((255-level)*(outH-1)/255)*tr.w:]
+
+				//When the output is wider than vf, a column of vf covers several output columns.
+				col := x * tr.w / width
+				for end := maxInt((x+1)*tr.w/width, col+1); col < end; col++ {
+					out[col]++
+				}
+			}
+		}
+
+		//The hits of one output column if its samples were spread evenly over its rows.
+		even := float32(height) * float32(maxInt(width, tr.w)) / float32(tr.w) / float32(outH)
+		scale := intensity / even
+		for y := 0; y < outH; y++ {
+			row := outData[y*int(output.LineStride)+tr.x0*4:]
+			for x := 0; x < tr.w; x++ {
+				n := hits[y*tr.w+x]
+				if n == 0 {
+					continue
+				}
+				v := float32(n) * scale
+				if v > 1 {
+					v = 1
+				}
+				for c := 0; c < 3; c++ {
+					row[x*4+c] = clampByte(v * float32(tr.color[c]))
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/* This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/. */
+
+package ndi
+
+import "testing"
+
+func TestDrawWaveform(t *testing.T) {
+	//Black on the left, white on the right.
+	src, _ := newTestVideoFrame(FourCCTypeBGRA, 16, 8, 4, func(x, y int) byte {
+		if x < 8 {
+			return 0
+		}
+		return 255
+	})
+	output, _ := newTestVideoFrame(FourCCTypeBGRA, 30, 10, 4, func(x, y int) byte { return 77 })
+
+	pixel := func(x, y int) [4]byte {
+		var px [4]byte
+		copy(px[:], output.ReadData()[y*int(output.LineStride)+x*4:])
+		return px
+	}
+
+	if err := DrawWaveform(src, output, WaveformOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		x, y int
+		want [4]byte
+	}{
+		{0, 9, [4]byte{255, 255, 255, 255}},
+		{0, 0, [4]byte{0, 0, 0, 255}},
+		{29, 0, [4]byte{255, 255, 255, 255}},
+		{29, 9, [4]byte{0, 0, 0, 255}},
+		{10, 5, [4]byte{0, 0, 0, 255}},
+	}
+	for _, c := range checks {
+		if px := pixel(c.x, c.y); px != c.want {
+			t.Errorf("Luma: Expected %v at %d,%d but got %v.", c.want, c.x, c.y, px)
+		}
+	}
+
+	//Pure red shows up at the top of the red panel and at the bottom of the others.
+	red, _ := newTestVideoFrame(FourCCTypeBGRA, 16, 8, 4, func(x, y int) byte { return 0 })
+	for i, data := 0, red.ReadData(); i < len(data); i += 4 {
+		data[i+2], data[i+3] = 255, 255
+	}
+	if err := DrawWaveform(red, output, WaveformOptions{Mode: WaveformRGBParade}); err != nil {
+		t.Fatal(err)
+	}
+	checks = []struct {
+		x, y int
+		want [4]byte
+	}{
+		{0, 0, [4]byte{0, 0, 255, 255}},
+		{0, 9, [4]byte{0, 0, 0, 255}},
+		{10, 9, [4]byte{0, 255, 0, 255}},
+		{29, 9, [4]byte{255, 0, 0, 255}},
+		{29, 0, [4]byte{0, 0, 0, 255}},
+	}
+	for _, c := range checks {
+		if px := pixel(c.x, c.y); px != c.want {
+			t.Errorf("Parade: Expected %v at %d,%d but got %v.", c.want, c.x, c.y, px)
+		}
+	}
+
+	//Mid grey UYVY lands in the middle.
+	grey, _ := newTestVideoFrame(FourCCTypeUYVY, 16, 8, 2, func(x, y int) byte { return 126 })
+	if err := DrawWaveform(grey, output, WaveformOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if px := pixel(15, 4); px != [4]byte{255, 255, 255, 255} {
+		t.Errorf("UYVY: Expected a trace at row 4 but got %v.", px)
+	}
+
+	small, _ := newTestVideoFrame(FourCCTypeBGRA, 2, 2, 4, func(x, y int) byte { return 0 })
+	uyvy, _ := newTestVideoFrame(FourCCTypeUYVY, 2, 2, 2, func(x, y int) byte { return 0 })
+	tests := []struct {
+		name        string
+		src, output *VideoFrameV2
+		mode        WaveformMode
+		err         error
+	}{
+		{"nil", nil, output, WaveformLuma, invalidVideoFrameErr},
+		{"output FourCC", src, uyvy, WaveformLuma, unsupportedFourCCErr},
+		{"source FourCC", &VideoFrameV2{FourCC: [4]byte{'N', 'V', '1', '2'}, Xres: 2, Yres: 2}, output, WaveformLuma, unsupportedFourCCErr},
+		{"parade too narrow", src, small, WaveformRGBParade, invalidResolutionErr},
+	}
+	for _, test := range tests {
+		if err := DrawWaveform(test.src, test.output, WaveformOptions{Mode: test.mode}); err != test.err {
+			t.Errorf("%s: Expected %v but got %v.", test.name, test.err, err)
+		}
+	}
+}